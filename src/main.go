@@ -46,7 +46,7 @@ func main() {
 		return
 	}
 	var removedStates []*representation.MorrisBoard
-	representation.GenerateRemove(board3, &removedStates)
+	representation.GenerateRemove(board3, representation.White, &removedStates)
 
 	fmt.Println("\nBoard used when considering GenerateRemove " + board3.String())
 	fmt.Println("\nPossible board states after black piece removal:")
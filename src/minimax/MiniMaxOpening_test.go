@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -22,3 +23,20 @@ func TestCommandLineArguments(t *testing.T) {
 		t.Errorf("Unexpected error: %v", err)
 	}
 }
+
+// Test that MiniMaxOpening accepts --eval anywhere and rejects unknown evaluators
+func TestEvalOption(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	output := filepath.Join(t.TempDir(), "board2.txt")
+	os.Args = []string{"main.go", "board1.txt", "--eval", "material", output, "2"}
+	if err := MiniMaxOpeningMain(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	os.Args = []string{"main.go", "--eval=nope", "board1.txt", output, "2"}
+	if err := MiniMaxOpeningMain(); err == nil {
+		t.Errorf("Expected an error for an unknown evaluator")
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"representation"
+	"representation/searchtest"
+	"testing"
+)
+
+// Test that the alpha-beta searcher of the binary returns the same estimate as plain minimax
+func TestAlphaBetaMidMatchesMinimax(t *testing.T) {
+	cfg := searchtest.Config{
+		Opening:   false,
+		Positions: 40,
+		MaxDepth:  3,
+		Seed:      1,
+		Generate:  representation.GenerateMovesMidgameEndgame,
+		Evaluator: mustLookupEvaluator(t, representation.DefaultEvaluator),
+	}
+
+	searchtest.CheckEquivalent(t, cfg, func(board *representation.MorrisBoard, player int, depth int, maximizingPlayer bool) int {
+		_, _, estimate := representation.LegacyMiniMaxAB(board, player, depth, representation.MidgameEndgame, cfg.Evaluator)
+		return estimate
+	})
+}
+
+func mustLookupEvaluator(t *testing.T, name string) representation.Evaluator {
+	t.Helper()
+	e, err := representation.LookupEvaluator(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
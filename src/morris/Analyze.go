@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"representation"
+	"strings"
+)
+
+func runAnalyzeCommand(args []string, out io.Writer) error {
+	usage := fmt.Errorf("usage: morris analyze [--side W|B] [--phase opening|midgame] [--depth N] [--eval NAME | --weights FILE] <input_file>")
+	evaluator, args, err := representation.ParseEvaluatorFlag(args)
+	if err != nil {
+		return err
+	}
+	flags := newFlagSet("analyze")
+	sideName := flags.String("side", "W", "side to move of a legacy board")
+	phaseName := flags.String("phase", "opening", "phase of a legacy board")
+	depth := flags.Int("depth", 4, "deepest search")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if len(positional) != 1 {
+		return usage
+	}
+	side, err := parseSide(*sideName)
+	if err != nil {
+		return err
+	}
+	phase, err := parsePhase(*phaseName)
+	if err != nil {
+		return err
+	}
+	if side == 0 || phase == -1 {
+		return usage
+	}
+
+	position, _, err := representation.ReadLegacyPosition(out, positional[0], side, phase)
+	if err != nil {
+		return err
+	}
+	if outcome := position.Outcome(); outcome != representation.Unfinished {
+		fmt.Fprintf(out, "Game over: %s\n", outcome)
+		return nil
+	}
+
+	// The static evaluation term by term, then the search at every depth
+	x := representation.ExplainEvaluation(evaluator, &position.Board, position.Phase(), position.SideToMove)
+	fmt.Fprint(out, representation.FormatExplanations([]string{"position"}, []representation.Explanation{x}))
+	for d := 1; d <= *depth; d++ {
+		result := representation.Search(position, d, evaluator)
+		fmt.Fprintf(out, "Depth %d: estimate %d, nodes %d, PV %s\n", d, result.Estimate, result.Nodes, formatMoves(result.PV))
+	}
+	return nil
+}
+
+// formatMoves returns moves in square notation, separated by spaces
+func formatMoves(moves []representation.Move) string {
+	names := make([]string, len(moves))
+	for i, m := range moves {
+		names[i] = m.String()
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"representation"
+	"time"
+)
+
+// benchPositions returns the fixed positions of the bench: openings and midgames reached by random plies
+// from the start position, the same on every run
+func benchPositions(n int) []representation.Position {
+	rng := rand.New(rand.NewSource(1))
+	positions := representation.OpeningSuite(rng, n-n/2, 6)
+	return append(positions, representation.OpeningSuite(rng, n/2, 24)...)
+}
+
+func runBenchCommand(args []string, out io.Writer) error {
+	usage := fmt.Errorf("usage: morris bench [--depth N] [--positions N] [--eval NAME | --weights FILE]")
+	evaluator, args, err := representation.ParseEvaluatorFlag(args)
+	if err != nil {
+		return err
+	}
+	flags := newFlagSet("bench")
+	depth := flags.Int("depth", 4, "search depth")
+	count := flags.Int("positions", 16, "positions searched")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if len(positional) != 0 || *depth < 1 || *count < 1 {
+		return usage
+	}
+
+	// The node counts depend only on the search and evaluator, so they tell changes to either apart
+	total, start := 0, time.Now()
+	for i, p := range benchPositions(*count) {
+		searchStart := time.Now()
+		result := representation.Search(p, *depth, evaluator)
+		fmt.Fprintf(out, "%2d %s: %s, %d nodes, %v\n", i+1, p.String(), result.Move.String(), result.Nodes, time.Since(searchStart).Round(time.Microsecond))
+		total += result.Nodes
+	}
+	elapsed := time.Since(start)
+	fmt.Fprintf(out, "Nodes: %d\n", total)
+	fmt.Fprintf(out, "Time: %v\n", elapsed.Round(time.Millisecond))
+	fmt.Fprintf(out, "Nodes/second: %d\n", int(float64(total)/max(elapsed.Seconds(), 1e-9)))
+	return nil
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"representation"
+	"strconv"
+	"strings"
+)
+
+// --- Interactive play
+//
+// A human plays one side against the engine by typing moves in square notation, or commands. The game is
+// kept as its start position and the positions after every move, so that undo and redo only move along
+// that list.
+
+// gridSquares places every board position on the 7 by 7 grid of its square name
+var gridSquares = func() map[[2]int]int {
+	grid := map[[2]int]int{}
+	for position, name := range representation.SquareNames {
+		grid[[2]int{int(name[0] - 'a'), int(name[1] - '0')}] = position
+	}
+	return grid
+}()
+
+// boardDiagram draws the board with row 6 at the top, a piece letter or '.' on every square
+func boardDiagram(board *representation.MorrisBoard) string {
+	var sb strings.Builder
+	for row := 6; row >= 0; row-- {
+		fmt.Fprintf(&sb, "%d ", row)
+		for column := 0; column < 7; column++ {
+			cell := " "
+			if position, found := gridSquares[[2]int{column, row}]; found {
+				cell = map[int]string{representation.Empty: ".", representation.White: "W", representation.Black: "B"}[board.GetPosition(position)]
+			}
+			sb.WriteString(" " + cell)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("   a b c d e f g\n")
+	return sb.String()
+}
+
+// session is an interactive game between a human and the engine
+type session struct {
+	in        *bufio.Scanner
+	out       io.Writer
+	engine    representation.Engine
+	human     int                       // Side the human plays
+	positions []representation.Position // Start position, then the position after every move
+	moves     []representation.RecordedMove
+	redo      []representation.RecordedMove // Moves taken back, the next one last
+}
+
+const interactiveHelp = `Commands:
+  a0, b3-b5, d4xg6  play a move in square notation; after a mill the piece to remove is asked for
+  moves             list the legal moves
+  undo, redo        take back or replay moves up to your next turn
+  switch            switch sides with the engine
+  depth N           set the engine's search depth
+  save FILE         save the game record
+  load FILE         load a game record
+  new               start a new game
+  help              show this help
+  quit              leave
+`
+
+func newSession(in io.Reader, out io.Writer, start representation.Position, human int, engine representation.Engine) *session {
+	return &session{in: bufio.NewScanner(in), out: out, engine: engine, human: human, positions: []representation.Position{start}}
+}
+
+func (s *session) position() representation.Position {
+	return s.positions[len(s.positions)-1]
+}
+
+// result returns the result of the game, Unfinished while it goes on
+func (s *session) result() string {
+	p := s.position()
+	if outcome := p.Outcome(); outcome != representation.Unfinished {
+		return outcome
+	}
+	if p.HalfmoveClock >= representation.DrawHalfmoves {
+		return representation.Draw
+	}
+	return representation.Unfinished
+}
+
+func (s *session) play(m representation.RecordedMove) error {
+	next, err := s.position().Play(m.Move)
+	if err != nil {
+		return err
+	}
+	s.positions = append(s.positions, next)
+	s.moves = append(s.moves, m)
+	return nil
+}
+
+// readLine prints the prompt and returns the next input line, false at the end of the input
+func (s *session) readLine(prompt string) (string, bool) {
+	fmt.Fprint(s.out, prompt)
+	if !s.in.Scan() {
+		return "", false
+	}
+	return strings.TrimSpace(s.in.Text()), true
+}
+
+// run plays until the input ends or the human quits
+func (s *session) run() error {
+	s.printPosition()
+	for {
+		p := s.position()
+		result := s.result()
+		if result == representation.Unfinished && p.SideToMove != s.human {
+			r := s.engine.Search(p)
+			s.play(representation.RecordedMove{Move: r.Move, Eval: r.Estimate, HasEval: true})
+			s.redo = nil
+			fmt.Fprintf(s.out, "Engine plays %s (estimate %d)\n", r.Move, r.Estimate)
+			s.printPosition()
+			continue
+		}
+
+		prompt := fmt.Sprintf("%s to move> ", colorName(p.SideToMove))
+		if result != representation.Unfinished {
+			prompt = fmt.Sprintf("Game over, %s> ", result)
+		}
+		line, ok := s.readLine(prompt)
+		if !ok {
+			fmt.Fprintln(s.out)
+			return nil
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "quit", "exit":
+			return nil
+		case "help", "?":
+			fmt.Fprint(s.out, interactiveHelp)
+		case "moves":
+			var moves []representation.Move
+			if result == representation.Unfinished {
+				moves = p.LegalMoves()
+			}
+			fmt.Fprintf(s.out, "Legal moves: %s\n", formatMoves(moves))
+		case "undo":
+			s.undo()
+		case "redo":
+			s.replay()
+		case "switch":
+			s.human = 3 - s.human
+			fmt.Fprintf(s.out, "You play %s\n", colorName(s.human))
+		case "depth":
+			if depth, err := strconv.Atoi(strings.Join(fields[1:], "")); err == nil && depth > 0 {
+				s.engine.Depth = depth
+				fmt.Fprintf(s.out, "Engine depth %d\n", depth)
+			} else {
+				fmt.Fprintln(s.out, "usage: depth N, with N at least 1")
+			}
+		case "save":
+			if len(fields) != 2 {
+				fmt.Fprintln(s.out, "usage: save FILE")
+			} else if err := s.save(fields[1]); err != nil {
+				fmt.Fprintf(s.out, "Cannot save: %v\n", err)
+			} else {
+				fmt.Fprintf(s.out, "Saved %s\n", fields[1])
+			}
+		case "load":
+			if len(fields) != 2 {
+				fmt.Fprintln(s.out, "usage: load FILE")
+			} else if err := s.load(fields[1]); err != nil {
+				fmt.Fprintf(s.out, "Cannot load: %v\n", err)
+			} else {
+				s.printPosition()
+			}
+		case "new":
+			s.positions, s.moves, s.redo = s.positions[:1], nil, nil
+			s.printPosition()
+		default:
+			if result != representation.Unfinished {
+				fmt.Fprintln(s.out, "The game is over; undo, load or start a new game")
+				continue
+			}
+			if m, ok := s.readMove(line); ok {
+				s.play(representation.RecordedMove{Move: m})
+				s.redo = nil
+				s.printPosition()
+			}
+		}
+	}
+}
+
+// readMove matches the input against the legal moves, asking for the piece to remove after a mill
+func (s *session) readMove(line string) (representation.Move, bool) {
+	m, err := representation.ParseMove(line)
+	if err != nil {
+		fmt.Fprintf(s.out, "%v; type help for the commands\n", err)
+		return m, false
+	}
+
+	var removals []int
+	for _, legal := range s.position().LegalMoves() {
+		if legal == m {
+			return m, true
+		}
+		if legal.From == m.From && legal.To == m.To && m.Remove == representation.NoSquare {
+			removals = append(removals, legal.Remove)
+		}
+	}
+	if len(removals) == 0 {
+		fmt.Fprintf(s.out, "Illegal move %s; type moves for the legal ones\n", m)
+		return m, false
+	}
+
+	// The move closes a mill, so one of the opponent's pieces is removed
+	names := make([]string, len(removals))
+	for i, remove := range removals {
+		names[i] = representation.SquareName(remove)
+	}
+	for {
+		answer, ok := s.readLine(fmt.Sprintf("Mill! Remove which piece (%s)? ", strings.Join(names, " ")))
+		if !ok {
+			return m, false
+		}
+		for _, remove := range removals {
+			if answer == representation.SquareName(remove) {
+				m.Remove = remove
+				return m, true
+			}
+		}
+	}
+}
+
+// undo takes back the last move, and the engine's moves before it, up to the human's turn
+func (s *session) undo() {
+	if len(s.moves) == 0 {
+		fmt.Fprintln(s.out, "Nothing to undo")
+		return
+	}
+	for len(s.moves) > 0 {
+		s.redo = append(s.redo, s.moves[len(s.moves)-1])
+		s.moves, s.positions = s.moves[:len(s.moves)-1], s.positions[:len(s.positions)-1]
+		if s.position().SideToMove == s.human {
+			break
+		}
+	}
+	s.printPosition()
+}
+
+// replay plays the moves taken back again, up to the human's next turn
+func (s *session) replay() {
+	if len(s.redo) == 0 {
+		fmt.Fprintln(s.out, "Nothing to redo")
+		return
+	}
+	for len(s.redo) > 0 {
+		m := s.redo[len(s.redo)-1]
+		s.redo = s.redo[:len(s.redo)-1]
+		s.play(m)
+		if s.position().SideToMove == s.human {
+			break
+		}
+	}
+	s.printPosition()
+}
+
+// game returns the record of the game so far
+func (s *session) game() *representation.Game {
+	g := &representation.Game{Start: s.positions[0], Moves: s.moves}
+	white, black := "Human", s.engine.Name
+	if s.human == representation.Black {
+		white, black = black, white
+	}
+	g.SetHeader("White", white)
+	g.SetHeader("Black", black)
+	g.SetHeader("Variant", "Morris-B")
+	if g.Start != representation.StartPosition() {
+		g.SetHeader("Position", g.Start.String())
+	}
+	g.SetResult(s.result())
+	return g
+}
+
+func (s *session) save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := representation.NewGameWriter(f).Write(s.game()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// load replaces the game with the first game of a record file, after checking its moves
+func (s *session) load(path string) error {
+	games, err := representation.ReadGameFile(path)
+	if err != nil {
+		return err
+	}
+	if len(games) == 0 {
+		return fmt.Errorf("%s holds no game", path)
+	}
+	g := games[0]
+	if _, err := representation.ValidateGame(g); err != nil {
+		return err
+	}
+	s.positions, s.moves, s.redo = []representation.Position{g.Start}, nil, nil
+	for _, m := range g.Moves {
+		s.play(m)
+	}
+	return nil
+}
+
+func (s *session) printPosition() {
+	p := s.position()
+	fmt.Fprint(s.out, boardDiagram(&p.Board))
+	fmt.Fprintf(s.out, "Move %d, %s to move, in hand White %d Black %d\n", p.MoveNumber, colorName(p.SideToMove), p.WhiteInHand, p.BlackInHand)
+}
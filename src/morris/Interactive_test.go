@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"representation"
+	"strings"
+	"testing"
+)
+
+func newTestSession(t *testing.T, input string, start string, human int) (*session, *bytes.Buffer) {
+	t.Helper()
+	p, err := representation.ParsePosition(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, _ := representation.LookupEvaluator(representation.DefaultEvaluator)
+	var out bytes.Buffer
+	engine := representation.Engine{Name: "test", Evaluator: e, Depth: 1}
+	return newSession(strings.NewReader(input), &out, p, human, engine), &out
+}
+
+// Test that a move closing a mill asks for the piece to remove, that undo and redo go back and forth
+// between the human's turns and that a saved game loads again
+func TestSessionMillUndoRedoSaveLoad(t *testing.T) {
+	record := filepath.Join(t.TempDir(), "game.txt")
+	s, out := newTestSession(t, "c2\nzz\nc3\nundo\nredo\nsave "+record+"\nquit\n", "WxWxxxxBBxxxxxxxxxxxx W 7 7 0 3", representation.White)
+	if err := s.run(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Mill! Remove which piece (b3 c3)? ") {
+		t.Errorf("no removal asked for:\n%s", out)
+	}
+	if len(s.moves) != 2 || s.moves[0].Move.String() != "c2xc3" || !s.moves[1].HasEval {
+		t.Fatalf("moves after redo: %+v", s.moves)
+	}
+
+	loaded, _ := newTestSession(t, "", "WxWxxxxBBxxxxxxxxxxxx W 7 7 0 3", representation.White)
+	if err := loaded.load(record); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.position() != s.position() || len(loaded.moves) != 2 {
+		t.Errorf("loaded %s after %d moves, want %s", loaded.position(), len(loaded.moves), s.position())
+	}
+	if g := s.game(); g.Header("White") != "Human" || g.Header("Black") != "test" {
+		t.Errorf("headers %+v", g.Headers)
+	}
+}
+
+// Test that switching sides makes the engine move, and that illegal moves are refused
+func TestSessionSwitchAndIllegalMoves(t *testing.T) {
+	s, out := newTestSession(t, "a0\na0\nb1-c2\nswitch\nquit\n", representation.StartPosition().String(), representation.White)
+	if err := s.run(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Illegal move a0") || !strings.Contains(out.String(), "Illegal move b1-c2") {
+		t.Errorf("illegal moves not refused:\n%s", out)
+	}
+	// a0, the engine's reply, then the engine's move for White after the switch
+	if len(s.moves) != 3 || s.human != representation.Black || s.position().SideToMove != representation.Black {
+		t.Errorf("%d moves, human %d, %s", len(s.moves), s.human, s.position())
+	}
+}
+
+// Test the board diagram
+func TestBoardDiagram(t *testing.T) {
+	board := representation.MorrisBoardFromString("WxxxxxxxxxxxxxxxxxxxB")
+	want := "6  .     .     B\n5    .   .   .  \n4      . . .    \n3  . . .   . . .\n2      .   .    \n1    .       .  \n0  W           .\n   a b c d e f g\n"
+	if got := boardDiagram(board); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"representation"
+	"sort"
+	"strings"
+)
+
+// --- Legacy binaries
+//
+// The six search binaries are representation.LegacyBinaries, run here by name.
+
+func runLegacyCommand(args []string, out io.Writer) error {
+	var names []string
+	for name := range representation.LegacyBinaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(args) == 0 {
+		return fmt.Errorf("usage: morris legacy %s <args>", strings.Join(names, "|"))
+	}
+	binary, found := representation.LegacyBinaries[args[0]]
+	if !found {
+		return fmt.Errorf("unknown legacy binary %q, want one of %s", args[0], strings.Join(names, ", "))
+	}
+	return binary.Run(args[1:], out)
+}
@@ -0,0 +1,560 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"representation"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- LAN game server
+//
+// Players connect over TCP and talk a line protocol, so that netcat or telnet is a client. They create games,
+// against another player or the engine, join them or watch them. The server plays every move through the
+// move generators, runs the clocks and appends finished games to a record file. Every message to a client
+// is one line starting with a keyword; a game's players and spectators get the same move, draw and result
+// messages.
+
+const lobbyHelp = `Commands:
+  name NAME                        choose your name
+  list                             list the games
+  create [W|B] [TIME [INCREMENT]]  open a game, such as create W 5m 3s; you play White by default
+  create [W|B] [TIME [INCREMENT]] engine DEPTH
+                                   play the engine at the given depth
+  join ID                          take the free side of a game
+  watch ID                         follow a game
+  move M                           play a move in square notation, such as a0, b3-b5 or d4xg6
+  draw                             offer a draw, or accept your opponent's offer
+  resign                           resign the game
+  leave                            stop playing or watching, resigning a game under way
+  help                             show this help
+  quit                             disconnect
+`
+
+// lobby holds the games and clients of the game server; mu guards every game and client field
+type lobby struct {
+	mu        sync.Mutex
+	games     map[int]*netGame
+	nextID    int
+	guests    int
+	evaluator representation.Evaluator // Of the engine opponents
+	records   string                   // Record file finished games are appended to, none if empty
+}
+
+// client is a connection to the lobby
+type client struct {
+	name     string
+	out      chan string // Lines to send, written by the connection's writer goroutine
+	game     *netGame    // Game being played or watched, nil if none
+	playing  int         // Color played in game, 0 if watching
+	closed   bool
+	closeOut sync.Once
+}
+
+// netGame is a game of the lobby
+type netGame struct {
+	id         int
+	names      [3]string  // Player of every color, empty while the seat is free
+	players    [3]*client // Connected player of every color, nil for the engine or a free seat
+	engine     *representation.Engine
+	spectators map[*client]bool
+	position   representation.Position
+	record     *representation.Game
+	clock      [3]time.Duration // Time left for every color
+	increment  time.Duration
+	turnStart  time.Time
+	timer      *time.Timer // Flags the side to move when its time runs out
+	drawOffer  int         // Color that offered a draw, 0 if none
+	started    bool
+}
+
+func newLobby(evaluator representation.Evaluator, records string) *lobby {
+	return &lobby{games: map[int]*netGame{}, nextID: 1, evaluator: evaluator, records: records}
+}
+
+// serve accepts connections until the listener is closed
+func (l *lobby) serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go l.handleConn(conn)
+	}
+}
+
+// handleConn runs the commands of a connection. Its writer goroutine closes the connection once the last
+// line is sent.
+func (l *lobby) handleConn(conn net.Conn) {
+	l.mu.Lock()
+	l.guests++
+	c := &client{name: fmt.Sprintf("guest%d", l.guests), out: make(chan string, 256)}
+	l.mu.Unlock()
+
+	go func() {
+		w := bufio.NewWriter(conn)
+		for line := range c.out {
+			w.WriteString(line + "\n")
+			if len(c.out) == 0 {
+				w.Flush()
+			}
+		}
+		w.Flush()
+		conn.Close() // Also unblocks the reader if the lobby dropped the client
+	}()
+
+	c.send("welcome %s; type help for the commands", c.name)
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" {
+			break
+		}
+		l.mu.Lock()
+		if err := l.handle(c, fields[0], fields[1:]); err != nil {
+			c.send("error %v", err)
+		}
+		l.mu.Unlock()
+	}
+
+	l.mu.Lock()
+	l.leave(c)
+	c.closed = true
+	c.closeOut.Do(func() { close(c.out) })
+	l.mu.Unlock()
+}
+
+// send queues a line for the client, dropping it if the client does not keep up. Called with l.mu held.
+func (c *client) send(format string, args ...any) {
+	if c.closed {
+		return
+	}
+	select {
+	case c.out <- fmt.Sprintf(format, args...):
+	default:
+		c.closed = true
+		c.closeOut.Do(func() { close(c.out) })
+	}
+}
+
+func (l *lobby) handle(c *client, cmd string, args []string) error {
+	if g := c.game; g != nil && g.started && g.record.Result != representation.Unfinished && (cmd == "create" || cmd == "join" || cmd == "watch") {
+		l.leave(c) // Done with the finished game
+	}
+	switch cmd {
+	case "help":
+		for _, line := range strings.Split(strings.TrimSuffix(lobbyHelp, "\n"), "\n") {
+			c.send("%s", line)
+		}
+	case "name":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: name NAME")
+		}
+		if c.game != nil {
+			return fmt.Errorf("cannot change names during a game")
+		}
+		c.name = args[0]
+		c.send("ok name %s", c.name)
+	case "list":
+		ids := make([]int, 0, len(l.games))
+		for id := range l.games {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			g := l.games[id]
+			c.send("game %d %s %s %s %s", id, seatName(g.names[representation.White]), seatName(g.names[representation.Black]), g.status(), g.timeControl())
+		}
+		c.send("ok %d games", len(ids))
+	case "create":
+		return l.create(c, args)
+	case "join":
+		g, err := l.gameArg(args)
+		if err != nil {
+			return err
+		}
+		return l.join(c, g)
+	case "watch":
+		g, err := l.gameArg(args)
+		if err != nil {
+			return err
+		}
+		if c.game != nil {
+			return fmt.Errorf("leave game %d first", c.game.id)
+		}
+		c.game, c.playing = g, 0
+		g.spectators[c] = true
+		c.send("ok watching %d", g.id)
+		g.sendState(c)
+	case "move":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: move M")
+		}
+		g, err := l.turn(c)
+		if err != nil {
+			return err
+		}
+		m, err := representation.ParseMove(args[0])
+		if err != nil {
+			return err
+		}
+		return l.play(g, m)
+	case "draw":
+		g, err := l.playing(c)
+		if err != nil {
+			return err
+		}
+		if g.drawOffer == 3-c.playing {
+			l.finish(g, representation.Draw, "draw agreed")
+			return nil
+		}
+		g.drawOffer = c.playing
+		g.broadcast("draw %d %s", g.id, colorName(c.playing))
+		if g.engine != nil {
+			g.send(c.playing, "info the engine declines the draw")
+			g.drawOffer = 0
+		}
+	case "resign":
+		g, err := l.playing(c)
+		if err != nil {
+			return err
+		}
+		l.finish(g, lossOf(c.playing), colorName(c.playing)+" resigns")
+	case "leave":
+		if c.game == nil {
+			return fmt.Errorf("not in a game")
+		}
+		l.leave(c)
+		c.send("ok left")
+	default:
+		return fmt.Errorf("unknown command %s", cmd)
+	}
+	return nil
+}
+
+// gameArg returns the game of an ID argument
+func (l *lobby) gameArg(args []string) (*netGame, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("a game ID is needed")
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || l.games[id] == nil {
+		return nil, fmt.Errorf("no game %s", args[0])
+	}
+	return l.games[id], nil
+}
+
+// create reads [W|B] [TIME [INCREMENT]] [engine DEPTH] and opens a game
+func (l *lobby) create(c *client, args []string) error {
+	if c.game != nil {
+		return fmt.Errorf("leave game %d first", c.game.id)
+	}
+	color, clock, increment := representation.White, 5*time.Minute, time.Duration(0)
+	if len(args) > 0 && (args[0] == "W" || args[0] == "B") {
+		color, _ = parseSide(args[0])
+		args = args[1:]
+	}
+	var engine *representation.Engine
+	if n := len(args); n >= 2 && args[n-2] == "engine" {
+		depth, err := strconv.Atoi(args[n-1])
+		if err != nil || depth < 1 || depth > 8 {
+			return fmt.Errorf("invalid engine depth %s", args[n-1])
+		}
+		engine = &representation.Engine{Name: fmt.Sprintf("morris-depth-%d", depth), Evaluator: l.evaluator, Depth: depth}
+		args = args[:n-2]
+	}
+	for i, value := range args {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 || i > 1 || i == 0 && d == 0 {
+			return fmt.Errorf("usage: create [W|B] [TIME [INCREMENT]] [engine DEPTH]")
+		}
+		if i == 0 {
+			clock = d
+		} else {
+			increment = d
+		}
+	}
+
+	g := &netGame{id: l.nextID, engine: engine, spectators: map[*client]bool{}, position: representation.StartPosition(), increment: increment}
+	g.clock[representation.White], g.clock[representation.Black] = clock, clock
+	l.nextID++
+	l.games[g.id] = g
+	g.names[color], g.players[color] = c.name, c
+	c.game, c.playing = g, color
+	c.send("ok created %d %s %s", g.id, colorName(color), g.timeControl())
+	if engine != nil {
+		g.names[3-color] = engine.Name
+		l.start(g)
+	}
+	return nil
+}
+
+func (l *lobby) join(c *client, g *netGame) error {
+	if c.game != nil {
+		return fmt.Errorf("leave game %d first", c.game.id)
+	}
+	color := 0
+	for _, seat := range []int{representation.White, representation.Black} {
+		if g.names[seat] == "" {
+			color = seat
+		}
+	}
+	if color == 0 || g.started {
+		return fmt.Errorf("game %d is full", g.id)
+	}
+	g.names[color], g.players[color] = c.name, c
+	c.game, c.playing = g, color
+	c.send("ok joined %d %s", g.id, colorName(color))
+	l.start(g)
+	return nil
+}
+
+// start begins the game once both seats are taken
+func (l *lobby) start(g *netGame) {
+	g.started = true
+	g.record = representation.NewGame(g.names[representation.White], g.names[representation.Black], time.Now().Format("2006.01.02"))
+	g.record.SetHeader("TimeControl", g.timeControl())
+	g.broadcast("start %d %s %s %s", g.id, g.names[representation.White], g.names[representation.Black], g.timeControl())
+	l.nextTurn(g)
+}
+
+// turn returns the game of the client if it is the client's turn
+func (l *lobby) turn(c *client) (*netGame, error) {
+	g, err := l.playing(c)
+	if err != nil {
+		return nil, err
+	}
+	if g.position.SideToMove != c.playing {
+		return nil, fmt.Errorf("not your turn")
+	}
+	return g, nil
+}
+
+// playing returns the game under way that the client plays in
+func (l *lobby) playing(c *client) (*netGame, error) {
+	if c.game == nil || c.playing == 0 {
+		return nil, fmt.Errorf("not playing a game")
+	}
+	if !c.game.started {
+		return nil, fmt.Errorf("waiting for an opponent")
+	}
+	if c.game.record.Result != representation.Unfinished {
+		return nil, fmt.Errorf("game %d is over", c.game.id)
+	}
+	return c.game, nil
+}
+
+// play plays a move of the side to move after checking it against the move generators, and charges its
+// clock
+func (l *lobby) play(g *netGame, m representation.Move) error {
+	next, err := g.position.Play(m)
+	if err != nil {
+		return fmt.Errorf("illegal move %s", m.String())
+	}
+	color := g.position.SideToMove
+	g.timer.Stop()
+	if elapsed := time.Since(g.turnStart); elapsed < g.clock[color] {
+		g.clock[color] += g.increment - elapsed
+	} else {
+		g.clock[color] = 0 // The flag fell while the move was on its way
+		l.finish(g, lossOf(color), colorName(color)+" lost on time")
+		return nil
+	}
+	g.position = next
+	g.record.Moves = append(g.record.Moves, representation.RecordedMove{Move: m})
+	if g.drawOffer == 3-color {
+		g.drawOffer = 0 // A move declines the opponent's offer
+	}
+	g.broadcast("move %d %s %s %s", g.id, m.String(), g.clocks(), next.String())
+
+	switch {
+	case next.Outcome() != representation.Unfinished:
+		l.finish(g, next.Outcome(), "")
+	case next.HalfmoveClock >= representation.DrawHalfmoves:
+		l.finish(g, representation.Draw, fmt.Sprintf("%d moves without placing or removing a piece", representation.DrawHalfmoves))
+	default:
+		l.nextTurn(g)
+	}
+	return nil
+}
+
+// nextTurn starts the clock of the side to move, and the engine's search if it is its turn
+func (l *lobby) nextTurn(g *netGame) {
+	color, ply := g.position.SideToMove, len(g.record.Moves)
+	g.turnStart = time.Now()
+	g.timer = time.AfterFunc(g.clock[color], func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if g.record.Result == representation.Unfinished && len(g.record.Moves) == ply {
+			g.clock[color] = 0
+			l.finish(g, lossOf(color), colorName(color)+" lost on time")
+		}
+	})
+	if g.engine == nil || g.players[color] != nil {
+		return
+	}
+	engine, p := *g.engine, g.position
+	go func() {
+		r := engine.Search(p)
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if g.record.Result == representation.Unfinished && len(g.record.Moves) == ply {
+			l.play(g, r.Move)
+		}
+	}()
+}
+
+// finish ends the game, tells its players and spectators, and appends it to the record file
+func (l *lobby) finish(g *netGame, result string, reason string) {
+	g.timer.Stop()
+	g.record.SetResult(result)
+	if reason != "" {
+		g.record.SetHeader("Termination", reason)
+	}
+	g.broadcast("result %d %s %s", g.id, result, reason)
+	if l.records != "" {
+		if err := appendRecord(l.records, g.record); err != nil {
+			g.broadcast("info game %d not saved: %v", g.id, err)
+		}
+	}
+}
+
+// leave takes the client out of its game, resigning it if it is under way, and removes games nobody plays
+// in any more
+func (l *lobby) leave(c *client) {
+	g := c.game
+	if g == nil {
+		return
+	}
+	c.game = nil
+	if c.playing == 0 {
+		delete(g.spectators, c)
+		return
+	}
+	if g.started && g.record.Result == representation.Unfinished {
+		l.finish(g, lossOf(c.playing), colorName(c.playing)+" left")
+	}
+	g.players[c.playing] = nil
+	if !g.started || g.players[representation.White] == nil && g.players[representation.Black] == nil {
+		for spectator := range g.spectators {
+			spectator.game = nil
+			spectator.send("info game %d closed", g.id)
+		}
+		delete(l.games, g.id)
+	}
+}
+
+// appendRecord appends a game to a record file
+func appendRecord(path string, g *representation.Game) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		f.WriteString("\n") // A blank line between games, as GameWriter writes them
+	}
+	if err := representation.NewGameWriter(f).Write(g); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func lossOf(color int) string {
+	if color == representation.White {
+		return representation.BlackWins
+	}
+	return representation.WhiteWins
+}
+
+func seatName(name string) string {
+	if name == "" {
+		return "-"
+	}
+	return name
+}
+
+// --- Game messages, called with l.mu held
+
+func (g *netGame) status() string {
+	switch {
+	case !g.started:
+		return "open"
+	case g.record.Result != representation.Unfinished:
+		return g.record.Result
+	}
+	return "playing"
+}
+
+func (g *netGame) timeControl() string {
+	return fmt.Sprintf("%v+%v", g.clock[representation.White].Round(time.Second), g.increment)
+}
+
+// clocks returns the time left of both sides in milliseconds
+func (g *netGame) clocks() string {
+	return fmt.Sprintf("%d %d", g.clock[representation.White].Milliseconds(), g.clock[representation.Black].Milliseconds())
+}
+
+// send writes to the player of a color, if connected
+func (g *netGame) send(color int, format string, args ...any) {
+	if c := g.players[color]; c != nil {
+		c.send(format, args...)
+	}
+}
+
+// broadcast writes to the players and spectators
+func (g *netGame) broadcast(format string, args ...any) {
+	g.send(representation.White, format, args...)
+	g.send(representation.Black, format, args...)
+	for c := range g.spectators {
+		c.send(format, args...)
+	}
+}
+
+// sendState catches a spectator up with the game: its players, moves and position
+func (g *netGame) sendState(c *client) {
+	c.send("game %d %s %s %s %s", g.id, seatName(g.names[representation.White]), seatName(g.names[representation.Black]), g.status(), g.timeControl())
+	if g.record != nil {
+		var moves []representation.Move
+		for _, rm := range g.record.Moves {
+			moves = append(moves, rm.Move)
+		}
+		c.send("moves %d %s", g.id, formatMoves(moves))
+	}
+	c.send("position %d %s %s", g.id, g.clocks(), g.position.String())
+}
+
+func runLobbyCommand(args []string, out io.Writer) error {
+	usage := fmt.Errorf("usage: morris lobby [--addr HOST:PORT] [--records FILE] [--eval NAME | --weights FILE]")
+	evaluator, args, err := representation.ParseEvaluatorFlag(args)
+	if err != nil {
+		return err
+	}
+	flags := newFlagSet("lobby")
+	addr := flags.String("addr", ":7000", "address to listen on, all interfaces by default for the LAN")
+	records := flags.String("records", "games.txt", "record file finished games are appended to")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if len(positional) != 0 {
+		return usage
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Game server listening on %s, connect with: nc HOST PORT\n", listener.Addr())
+	return newLobby(evaluator, *records).serve(listener)
+}
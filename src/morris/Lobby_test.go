@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"representation"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lobbyClient is a connection to a test lobby
+type lobbyClient struct {
+	t    *testing.T
+	conn net.Conn
+	in   *bufio.Scanner
+}
+
+func newTestLobby(t *testing.T) (addr string, records string) {
+	t.Helper()
+	e, _ := representation.LookupEvaluator(representation.DefaultEvaluator)
+	records = filepath.Join(t.TempDir(), "games.txt")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go newLobby(e, records).serve(listener)
+	return listener.Addr().String(), records
+}
+
+func dialLobby(t *testing.T, addr string, name string) *lobbyClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	c := &lobbyClient{t: t, conn: conn, in: bufio.NewScanner(conn)}
+	c.expect("welcome ")
+	c.send("name " + name)
+	c.expect("ok name " + name)
+	return c
+}
+
+func (c *lobbyClient) send(line string) {
+	fmt.Fprintln(c.conn, line)
+}
+
+// expect reads lines until one starts with prefix, and returns it
+func (c *lobbyClient) expect(prefix string) string {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	var seen []string
+	for c.in.Scan() {
+		if line := c.in.Text(); strings.HasPrefix(line, prefix) {
+			return line
+		} else {
+			seen = append(seen, line)
+		}
+	}
+	c.t.Fatalf("no line starting with %q after %q: %v", prefix, seen, c.in.Err())
+	return ""
+}
+
+// Test a game between two players with a spectator: moves are checked and sent to everybody, draw offers
+// are accepted and the finished game is recorded
+func TestLobbyGame(t *testing.T) {
+	addr, records := newTestLobby(t)
+	alice, bob, carol := dialLobby(t, addr, "alice"), dialLobby(t, addr, "bob"), dialLobby(t, addr, "carol")
+
+	alice.send("create W 1m 1s")
+	alice.expect("ok created 1 White 1m0s+1s")
+	bob.send("list")
+	if line := bob.expect("game "); line != "game 1 alice - open 1m0s+1s" {
+		t.Errorf("list: %s", line)
+	}
+	bob.send("join 1")
+	bob.expect("ok joined 1 Black")
+	for _, c := range []*lobbyClient{alice, bob} {
+		if line := c.expect("start "); line != "start 1 alice bob 1m0s+1s" {
+			t.Errorf("start: %s", line)
+		}
+	}
+
+	bob.send("move a0")
+	bob.expect("error not your turn")
+	alice.send("move a0")
+	bob.expect("move 1 a0 ")
+	carol.send("watch 1")
+	carol.expect("ok watching 1")
+	if line := carol.expect("moves "); line != "moves 1 a0" {
+		t.Errorf("moves: %s", line)
+	}
+	bob.send("move a0")
+	bob.expect("error illegal move a0")
+	bob.send("move g6")
+	for _, c := range []*lobbyClient{alice, carol} {
+		c.expect("move 1 g6 ")
+	}
+
+	alice.send("draw")
+	bob.expect("draw 1 White")
+	bob.send("draw")
+	for _, c := range []*lobbyClient{alice, bob, carol} {
+		if line := c.expect("result "); line != "result 1 1/2-1/2 draw agreed" {
+			t.Errorf("result: %s", line)
+		}
+	}
+
+	alice.send("list") // The list waits for the game to be recorded
+	alice.expect("ok ")
+	f, err := os.Open(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := representation.NewGameReader(f).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Header("White") != "alice" || g.Header("Black") != "bob" || g.Result != representation.Draw || len(g.Moves) != 2 ||
+		g.Header("Termination") != "draw agreed" || g.Header("TimeControl") != "1m0s+1s" {
+		t.Errorf("record: %+v", g)
+	}
+}
+
+// Test resigning, leaving and the clock
+func TestLobbyEndings(t *testing.T) {
+	addr, records := newTestLobby(t)
+	alice, bob := dialLobby(t, addr, "alice"), dialLobby(t, addr, "bob")
+
+	alice.send("create B")
+	alice.expect("ok created 1 Black 5m0s+0s")
+	bob.send("join 1")
+	bob.expect("ok joined 1 White")
+	bob.send("resign")
+	if line := alice.expect("result "); line != "result 1 0-1 White resigns" {
+		t.Errorf("resign: %s", line)
+	}
+	bob.send("resign")
+	bob.expect("error game 1 is over")
+
+	// Creating leaves the finished game
+	alice.send("create W 200ms")
+	alice.expect("ok created 2 White")
+	bob.send("join 2")
+	bob.expect("ok joined 2 Black")
+	if line := bob.expect("result "); line != "result 2 0-1 White lost on time" {
+		t.Errorf("flag: %s", line)
+	}
+
+	alice.send("create")
+	alice.expect("ok created 3 White")
+	bob.send("join 3")
+	bob.expect("ok joined 3 Black")
+	bob.conn.Close()
+	if line := alice.expect("result "); line != "result 3 1-0 Black left" {
+		t.Errorf("leave: %s", line)
+	}
+
+	alice.send("list")
+	alice.expect("ok ")
+	data, err := os.ReadFile(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := representation.NewGameReader(strings.NewReader(string(data)))
+	for _, want := range []string{"White resigns", "White lost on time", "Black left"} {
+		g, err := r.Read()
+		if err != nil || g.Header("Termination") != want {
+			t.Fatalf("record of %q: %v %v", want, g, err)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("extra record: %v", err)
+	}
+}
+
+// Test a game against the engine, which answers every move
+func TestLobbyEngine(t *testing.T) {
+	addr, _ := newTestLobby(t)
+	alice := dialLobby(t, addr, "alice")
+	alice.send("create B 1m engine 2")
+	alice.expect("ok created 1 Black")
+	if line := alice.expect("start "); line != "start 1 morris-depth-2 alice 1m0s+0s" {
+		t.Errorf("start: %s", line)
+	}
+	line := alice.expect("move 1 ")
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		t.Fatalf("engine move: %s", line)
+	}
+	alice.send("draw")
+	alice.expect("info the engine declines the draw")
+	p, _ := representation.StartPosition().Play(mustParseMove(t, fields[2]))
+	moves := p.LegalMoves()
+	alice.send("move " + moves[0].String())
+	alice.expect("move 1 " + moves[0].String())
+	alice.expect("move 1 ")
+}
+
+func mustParseMove(t *testing.T, s string) representation.Move {
+	t.Helper()
+	m, err := representation.ParseMove(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"representation"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const engineSpecUsage = "  SPEC: comma separated name=NAME, eval=NAME, weights=FILE, algo=ab|minimax, depth=N, time=DURATION"
+
+// engineFlags collects the engine specs of repeated --engine options
+type engineFlags []string
+
+func (e *engineFlags) String() string { return strings.Join(*e, " ") }
+
+func (e *engineFlags) Set(spec string) error {
+	*e = append(*e, spec)
+	return nil
+}
+
+// parseEngine reads an engine spec of comma separated key=value pairs: name, eval, weights, algo (ab or
+// minimax), depth and time per move, such as "eval=tapered,depth=4" or "algo=ab,time=200ms". A timed engine
+// deepens its search without a limit unless a depth is given too. The name defaults to the evaluator or
+// weights file and the depth or time.
+func parseEngine(spec string) (representation.Engine, error) {
+	name, evalName := "", representation.DefaultEvaluator
+	var evalArgs []string
+	e := representation.Engine{Depth: -1, Algorithm: representation.AlgorithmAlphaBeta}
+	for _, field := range strings.Split(spec, ",") {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			return representation.Engine{}, fmt.Errorf("invalid engine spec %q: %s is no key=value pair", spec, field)
+		}
+		switch key {
+		case "name":
+			name = value
+		case "eval", "weights":
+			evalName = value
+			evalArgs = append(evalArgs, "--"+key, value)
+		case "algo":
+			if value != representation.AlgorithmAlphaBeta && value != representation.AlgorithmMiniMax {
+				return representation.Engine{}, fmt.Errorf("invalid engine spec %q: algo %s", spec, value)
+			}
+			e.Algorithm = value
+		case "depth":
+			d, err := strconv.Atoi(value)
+			if err != nil || d < 1 {
+				return representation.Engine{}, fmt.Errorf("invalid engine spec %q: depth %s", spec, value)
+			}
+			e.Depth = d
+		case "time":
+			t, err := time.ParseDuration(value)
+			if err != nil || t <= 0 {
+				return representation.Engine{}, fmt.Errorf("invalid engine spec %q: time %s", spec, value)
+			}
+			e.MoveTime = t
+		default:
+			return representation.Engine{}, fmt.Errorf("invalid engine spec %q: unknown key %s", spec, key)
+		}
+	}
+	evaluator, _, err := representation.ParseEvaluatorFlag(evalArgs)
+	if err != nil {
+		return representation.Engine{}, err
+	}
+	e.Evaluator = evaluator
+
+	switch {
+	case e.Depth == -1 && e.MoveTime > 0:
+		e.Depth = 0
+	case e.Depth == -1:
+		e.Depth = 3
+	}
+	if name == "" {
+		name = evalName
+		if e.Algorithm != representation.AlgorithmAlphaBeta {
+			name += " " + e.Algorithm
+		}
+		if e.Depth > 0 {
+			name += fmt.Sprintf(" depth %d", e.Depth)
+		}
+		if e.MoveTime > 0 {
+			name += " time " + e.MoveTime.String()
+		}
+	}
+	e.Name = name
+	return e, nil
+}
+
+// matchOptions are the settings of a match between two engines
+type matchOptions struct {
+	Games        int
+	Concurrency  int
+	OpeningPlies int
+	MaxPlies     int
+	Seed         int64
+	SPRT         representation.SPRT
+}
+
+// gameJob is a game of a match or tournament
+type gameJob struct {
+	White, Black representation.Engine
+	Start        representation.Position
+}
+
+// playGames plays the jobs on concurrent workers, numbering the games by their Round header. finished is
+// called under a lock as every game ends; no game is started once it returns true. The games are returned in
+// the order of the jobs, nil for those not played.
+func playGames(jobs []gameJob, concurrency int, maxPlies int, finished func(index int, g *representation.Game) bool) []*representation.Game {
+	games := make([]*representation.Game, len(jobs))
+	indices := make(chan int)
+	done := make(chan struct{})
+	var mu sync.Mutex
+
+	var wg sync.WaitGroup
+	for w := 0; w < max(concurrency, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				j := jobs[index]
+				g := representation.PlayGame(j.White, j.Black, j.Start, maxPlies)
+				g.SetHeader("Round", strconv.Itoa(index+1))
+
+				mu.Lock()
+				games[index] = g
+				if finished(index, g) {
+					select {
+					case <-done:
+					default:
+						close(done)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+schedule:
+	for index := range jobs {
+		select {
+		case indices <- index:
+		case <-done:
+			break schedule
+		}
+	}
+	close(indices)
+	wg.Wait()
+	return games
+}
+
+// balancedOpenings returns the balanced openings of games played in pairs, one opening for both colors of a
+// pair, or an error if the suite holds fewer than the pairs need
+func balancedOpenings(seed int64, games int, plies int) ([]representation.Position, error) {
+	pairs := (games + 1) / 2
+	openings := representation.BalancedOpeningSuite(rand.New(rand.NewSource(seed)), pairs, plies)
+	if len(openings) < pairs {
+		return nil, fmt.Errorf("found %d balanced openings of %d plies, %d games need %d: raise --opening-plies or lower --games",
+			len(openings), plies, games, pairs)
+	}
+	return openings, nil
+}
+
+// playMatch plays the games of a match, the first engine taking White in even and Black in odd games, both
+// colors of a pair starting from the same balanced opening. Every game is reported to progress as it ends;
+// no game is started once the SPRT reaches a verdict. The result holds the games in the order they were
+// scheduled.
+func playMatch(a representation.Engine, b representation.Engine, o matchOptions, progress func(n int, g *representation.Game, r representation.MatchResult)) (representation.MatchResult, error) {
+	var r representation.MatchResult
+	openings, err := balancedOpenings(o.Seed, o.Games, o.OpeningPlies)
+	if err != nil {
+		return r, err
+	}
+	var jobs []gameJob
+	for index := 0; index < o.Games; index++ {
+		if index%2 == 0 {
+			jobs = append(jobs, gameJob{a, b, openings[index/2]})
+		} else {
+			jobs = append(jobs, gameJob{b, a, openings[index/2]})
+		}
+	}
+
+	games := playGames(jobs, o.Concurrency, o.MaxPlies, func(index int, g *representation.Game) bool {
+		r.Count(g, index%2 == 0)
+		progress(len(r.Games), g, r)
+		return o.SPRT.Verdict(r) != representation.SPRTContinue
+	})
+	r.Games = r.Games[:0]
+	for _, g := range games {
+		if g != nil {
+			r.Games = append(r.Games, g)
+		}
+	}
+	return r, nil
+}
+
+func runMatchCommand(args []string, out io.Writer) error {
+	usage := fmt.Errorf("usage: morris match --engine SPEC --engine SPEC [--games N] [--concurrency N] [--opening-plies N] [--max-plies N] [--seed N] [--elo0 E] [--elo1 E] [--alpha A] [--beta B] [--out FILE]\n" +
+		engineSpecUsage)
+	flags := newFlagSet("match")
+	var engines engineFlags
+	flags.Var(&engines, "engine", "engine spec, given twice")
+	var o matchOptions
+	flags.IntVar(&o.Games, "games", 100, "games to play")
+	flags.IntVar(&o.Concurrency, "concurrency", runtime.NumCPU(), "games played at the same time")
+	flags.IntVar(&o.OpeningPlies, "opening-plies", 8, "random plies of the openings")
+	flags.IntVar(&o.MaxPlies, "max-plies", 200, "plies after which a game is drawn")
+	flags.Int64Var(&o.Seed, "seed", 1, "seed of the opening suite")
+	flags.Float64Var(&o.SPRT.Elo0, "elo0", 0, "Elo difference of the SPRT null hypothesis")
+	flags.Float64Var(&o.SPRT.Elo1, "elo1", 20, "Elo difference of the SPRT alternative hypothesis")
+	flags.Float64Var(&o.SPRT.Alpha, "alpha", 0.05, "SPRT false positive rate")
+	flags.Float64Var(&o.SPRT.Beta, "beta", 0.05, "SPRT false negative rate")
+	output := flags.String("out", "", "record file of the games")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if len(positional) != 0 || len(engines) != 2 || o.Games < 1 || o.SPRT.Alpha <= 0 || o.SPRT.Beta <= 0 || o.SPRT.Alpha+o.SPRT.Beta >= 1 {
+		return usage
+	}
+	a, err := parseEngine(engines[0])
+	if err != nil {
+		return err
+	}
+	b, err := parseEngine(engines[1])
+	if err != nil {
+		return err
+	}
+	if a.Name == b.Name {
+		b.Name += " (2)" // The records tell the engines apart by name
+	}
+
+	r, err := playMatch(a, b, o, func(n int, g *representation.Game, r representation.MatchResult) {
+		fmt.Fprintf(out, "Game %d (%s vs %s): %s, score %d - %d - %d\n", n, g.Header("White"), g.Header("Black"), g.Result, r.Wins, r.Losses, r.Draws)
+	})
+	if err != nil {
+		return err
+	}
+
+	games := r.Wins + r.Draws + r.Losses
+	fmt.Fprintf(out, "Score of %s vs %s: %d - %d - %d [%.3f] %d\n", a.Name, b.Name, r.Wins, r.Losses, r.Draws, r.Score(), games)
+	elo, margin := r.Elo()
+	fmt.Fprintf(out, "Elo difference: %.1f +/- %.1f\n", elo, margin)
+	lower, upper := o.SPRT.Bounds()
+	fmt.Fprintf(out, "SPRT (elo0 %g, elo1 %g, alpha %g, beta %g): LLR %.2f (%.2f, %.2f), %s\n", o.SPRT.Elo0, o.SPRT.Elo1, o.SPRT.Alpha, o.SPRT.Beta, o.SPRT.LLR(r), lower, upper, o.SPRT.Verdict(r))
+	if games < o.Games {
+		fmt.Fprintf(out, "Stopped after %d of %d games\n", games, o.Games)
+	}
+
+	if *output == "" {
+		return nil
+	}
+	return writeGames(*output, r.Games)
+}
+
+// writeGames writes the games to a record file
+func writeGames(path string, games []*representation.Game) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write game records: %v", err)
+	}
+	gw := representation.NewGameWriter(f)
+	for _, g := range games {
+		if err := gw.Write(g); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"path/filepath"
+	"representation"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test that the match alternates colors on every opening and saves the games in order
+func TestMatchCommand(t *testing.T) {
+	record := filepath.Join(t.TempDir(), "games.txt")
+	got, err := run(t, "morris", "match", "--engine", "name=A,eval=material,depth=1", "--engine", "name=B,depth=2", "--games", "4", "--concurrency", "2", "--out", record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Score of A vs B: ", "Elo difference: ", "SPRT (elo0 0, elo1 20, alpha 0.05, beta 0.05): LLR "} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output misses %q:\n%s", want, got)
+		}
+	}
+
+	games, err := representation.ReadGameFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 4 {
+		t.Fatalf("%d games saved", len(games))
+	}
+	for i, g := range games {
+		white := "A"
+		if i%2 == 1 {
+			white = "B"
+		}
+		if g.Header("Round") != string(rune('1'+i)) || g.Header("White") != white || g.Result == representation.Unfinished {
+			t.Errorf("game %d: %+v, result %s", i+1, g.Headers, g.Result)
+		}
+		if _, err := representation.ValidateGame(g); err != nil {
+			t.Errorf("game %d: %v", i+1, err)
+		}
+	}
+	if games[0].Start != games[1].Start || games[0].Start == games[2].Start {
+		t.Error("pairs do not share their opening")
+	}
+}
+
+// Test that no game is started once the SPRT reaches a verdict
+func TestMatchStopsOnSPRT(t *testing.T) {
+	got, err := run(t, "morris", "match", "--engine", "depth=1", "--engine", "depth=3", "--games", "40", "--concurrency", "1", "--alpha", "0.45", "--beta", "0.45")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "accepted") || !strings.Contains(got, "Stopped after ") {
+		t.Errorf("match did not stop:\n%s", got)
+	}
+}
+
+// Test that a match needing more openings than the suite holds is refused rather than cut short
+func TestMatchTooFewOpenings(t *testing.T) {
+	got, err := run(t, "morris", "match", "--engine", "depth=1", "--engine", "depth=2", "--games", "10", "--opening-plies", "0")
+	if err == nil || !strings.Contains(err.Error(), "balanced openings") || got != "" {
+		t.Errorf("got %q, %v", got, err)
+	}
+}
+
+// Test the engine specs
+func TestParseEngine(t *testing.T) {
+	e, err := parseEngine("eval=tapered,depth=4")
+	if err != nil || e.Name != "tapered depth 4" || e.Depth != 4 {
+		t.Errorf("got %+v, %v", e, err)
+	}
+	if e, err = parseEngine("algo=minimax,time=50ms"); err != nil || e.Name != "standard minimax time 50ms" || e.Depth != 0 || e.MoveTime != 50*time.Millisecond {
+		t.Errorf("got %+v, %v", e, err)
+	}
+	for _, spec := range []string{"depth", "depth=0", "eval=none", "speed=3", "algo=mcts", "time=fast"} {
+		if _, err := parseEngine(spec); err == nil {
+			t.Errorf("%s accepted", spec)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"representation"
+	"sort"
+	"strings"
+)
+
+/*
+Requirements:
+
+ 1. get command line args
+    X a subcommand: search, perft, play, tui, analyze, bench, match, tournament, uci, serve, web, lobby or legacy
+    X its options, before or after its input file:
+      --side W|B (side to move of a legacy board), --phase opening|midgame (phase of a legacy board),
+      --algo minimax|ab|full, --depth N, --eval NAME, --weights FILE, --explain, --out FILE
+    X match: two --engine specs of name, eval, weights, algo, depth and time per move, --games N, --concurrency N, --opening-plies N,
+      --max-plies N, --seed N, and the SPRT hypotheses and error rates --elo0, --elo1, --alpha, --beta
+    X tournament: a config file of engine specs, one per line, --mode round-robin|gauntlet, --games N per
+      pairing, --concurrency N, --opening-plies N, --max-plies N, --seed N, --ratings FILE, --json FILE
+    X lobby: --addr HOST:PORT to listen on and --records FILE for the finished games
+    X legacy NAME followed by the positional args of one of the six search binaries, or the binary name itself
+      when morris is installed under it (MiniMaxOpening, ABOpening, MiniMaxOpeningBlack, MiniMaxGame, ABGame,
+      MiniMaxGameBlack)
+
+2. output:
+  - search: the same lines as the legacy binaries, the output position also to --out if given
+  - perft: the same lines as Perft
+  - play: the moves of an engine game with their estimates and the result, the game record also to --out;
+    with --human W|B an interactive game against the engine, with undo/redo, save/load and switching sides
+  - tui: a full-screen game against the engine on a Linux terminal, the board drawn with its lines, a
+    cursor to select pieces, destinations and captures, and the engine's PV, score and nodes as it thinks
+  - analyze: the evaluation terms of the position and the search result at every depth up to --depth
+  - bench: nodes and time of a fixed set of searches, and the nodes per second
+  - match: the result of every game as it ends, then W/L/D, the Elo difference with its 95% error margin and
+    the SPRT verdict, every game also to --out; the engines alternate colors from balanced openings
+  - uci: replies to the commands of a UCI-style protocol read from stdin: uci, isready, setoption,
+    ucinewgame, position startpos|extended POSITION [moves M...], go [depth N] [movetime MS] [infinite], stop,
+    d and quit; info lines for every search depth and a bestmove at the end of the search
+  - serve: an HTTP server on --addr answering POSTs of JSON to /api/validate, /api/moves, /api/eval,
+    /api/search and /api/perft; searches and perft counts run on --workers workers and are cancelled after
+    --timeout or when the client disconnects
+  - web: the API of serve, and on / an embedded page to play the engine in the browser offline: the board
+    drawn as SVG, the move history, an evaluation bar and the PV, the search followed live through the
+    Server-Sent Events of /api/stream
+  - lobby: a TCP game server for the LAN speaking a line protocol: players name themselves, list, create,
+    join or watch games, against each other or the engine, and play moves, offer draws and resign; the server
+    checks every move, runs the clocks, sends moves and results to players and spectators, and appends every
+    finished game to --records
+  - tournament: the result of every game as it ends, then the crosstable with the BayesElo of the tournament,
+    also as JSON to --json, and the rating list of --ratings updated with the results; every game to --out
+  - legacy: exactly what the binary prints and writes
+
+The minimax and ab algorithms are the searchers of the legacy binaries, every ply in the given phase. The full
+algorithm searches extended positions, whose trees cross from the opening into the midgame.
+*/
+
+// command is a subcommand of morris
+type command struct {
+	run     func(args []string, out io.Writer) error
+	summary string
+}
+
+var commands = map[string]command{
+	"search":     {runSearchCommand, "search a position for the best move"},
+	"perft":      {runPerftCommand, "count the leaf positions of the game tree"},
+	"play":       {runPlayCommand, "play an engine game"},
+	"analyze":    {runAnalyzeCommand, "break down the evaluation and search at every depth"},
+	"bench":      {runBenchCommand, "time the search of a fixed set of positions"},
+	"legacy":     {runLegacyCommand, "run one of the legacy search binaries"},
+	"tui":        {runTUICommand, "play the engine in a full-screen terminal UI"},
+	"match":      {runMatchCommand, "play a match between two engines and estimate their Elo difference"},
+	"serve":      {runServeCommand, "serve the engine as an HTTP JSON API"},
+	"web":        {runWebCommand, "play the engine in a browser"},
+	"lobby":      {runLobbyCommand, "host games between players on the LAN, with clocks and spectators"},
+	"uci":        {runProtocolCommand, "talk a UCI-style protocol over stdin and stdout"},
+	"tournament": {runTournamentCommand, "play a round-robin or gauntlet tournament and update the rating list"},
+}
+
+func MorrisMain(args []string, out io.Writer) error {
+	// Installed under a legacy binary name, behave as that binary
+	name := strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+	if binary, found := representation.LegacyBinaries[name]; found {
+		return binary.Run(args[1:], out)
+	}
+
+	if len(args) < 2 {
+		return usage()
+	}
+	cmd, found := commands[args[1]]
+	if !found {
+		return fmt.Errorf("unknown command %q\n%v", args[1], usage())
+	}
+	return cmd.run(args[2:], out)
+}
+
+func usage() error {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString("usage: morris <command> [options] [args]\n")
+	for _, name := range names {
+		fmt.Fprintf(&sb, "  %-8s %s\n", name, commands[name].summary)
+	}
+	return fmt.Errorf("%s", strings.TrimSuffix(sb.String(), "\n"))
+}
+
+// --- Options shared by the commands
+
+// newFlagSet returns a flag set that reports its errors instead of printing them
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// parseFlags parses options placed before, between or after the positional args, and returns the latter
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional, args = append(positional, args[0]), args[1:]
+	}
+}
+
+// parseSide reads the side to move of --side, 0 if it is empty
+func parseSide(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return 0, nil
+	case "w", "white":
+		return representation.White, nil
+	case "b", "black":
+		return representation.Black, nil
+	}
+	return 0, fmt.Errorf("invalid side: %s", s)
+}
+
+// parsePhase reads the phase of --phase, -1 if it is empty
+func parsePhase(s string) (int, error) {
+	switch s {
+	case "":
+		return -1, nil
+	case "opening":
+		return representation.Opening, nil
+	case "midgame":
+		return representation.MidgameEndgame, nil
+	}
+	return 0, fmt.Errorf("invalid phase: %s", s)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"representation"
+	"strings"
+	"testing"
+)
+
+func writeInput(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := MorrisMain(args, &out)
+	return out.String(), err
+}
+
+// Test that every legacy binary, run by name or through the legacy command, prints and writes the result of
+// its searcher, and that the search command with the matching options prints the same
+func TestLegacyBinaries(t *testing.T) {
+	inputs := map[int]string{
+		representation.Opening:        "xxxxxxWxxBxxxxxxBxWxx",
+		representation.MidgameEndgame: "xBxBWxxxxBxBWWxWWWBBB",
+	}
+	e, _ := representation.LookupEvaluator(representation.DefaultEvaluator)
+	for name, binary := range representation.LegacyBinaries {
+		input := writeInput(t, inputs[binary.Phase])
+		board := representation.MorrisBoardFromString(inputs[binary.Phase])
+		search, algo := representation.LegacyMiniMax, AlgoMiniMax
+		if binary.AlphaBeta {
+			search, algo = representation.LegacyMiniMaxAB, AlgoAB
+		}
+		best, nodes, estimate := search(board, binary.Side, 2, binary.Phase, e)
+		want := fmt.Sprintf("Input position: %s\nOutput position: %s\nOutput move: %s\nPositions evaluated by static estimation: %d\nMINIMAX estimate: %d\n",
+			inputs[binary.Phase], best, representation.MoveBetween(board, best, binary.Side), nodes, estimate)
+
+		output := filepath.Join(t.TempDir(), "output.txt")
+		byName, err := run(t, "/usr/local/bin/"+name+".exe", input, output, "2")
+		if err != nil || byName != want {
+			t.Errorf("%s: got %q, %v, want %q", name, byName, err, want)
+		}
+		if written, _ := os.ReadFile(output); string(written) != best.String() {
+			t.Errorf("%s: wrote %q, want %q", name, written, best)
+		}
+		if legacy, err := run(t, "morris", "legacy", name, "--eval", "standard", input, output, "2"); err != nil || legacy != want {
+			t.Errorf("morris legacy %s: got %q, %v", name, legacy, err)
+		}
+
+		phase := map[int]string{representation.Opening: "opening", representation.MidgameEndgame: "midgame"}[binary.Phase]
+		side := map[int]string{representation.White: "W", representation.Black: "B"}[binary.Side]
+		if searched, err := run(t, "morris", "search", input, "--side", side, "--phase", phase, "--algo", algo, "--depth", "2"); err != nil || searched != want {
+			t.Errorf("morris search as %s: got %q, %v", name, searched, err)
+		}
+	}
+}
+
+// Test the usage errors of the legacy binaries
+func TestLegacyErrors(t *testing.T) {
+	if _, err := run(t, "ABGame", "a", "b"); err == nil || !strings.HasPrefix(err.Error(), "usage: MiniMaxMid ") {
+		t.Errorf("too few args: %v", err)
+	}
+	if _, err := run(t, "MiniMaxOpening", "a", "b", "c"); err == nil || err.Error() != "invalid depth: c" {
+		t.Errorf("invalid depth: %v", err)
+	}
+	if _, err := run(t, "morris", "legacy", "Minimax"); err == nil || !strings.Contains(err.Error(), "unknown legacy binary") {
+		t.Errorf("unknown binary: %v", err)
+	}
+}
+
+// Test that the full algorithm searches an extended position across the phases and writes its successor
+func TestSearchFull(t *testing.T) {
+	input := writeInput(t, "WWxxxxxxBBxxxxxxxxxxx W 1 7 0 9")
+	output := filepath.Join(t.TempDir(), "output.txt")
+	got, err := run(t, "morris", "search", "--algo", "full", "--depth", "3", "--out", output, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	position, _ := representation.ParsePosition("WWxxxxxxBBxxxxxxxxxxx W 1 7 0 9")
+	e, _ := representation.LookupEvaluator(representation.DefaultEvaluator)
+	result := representation.Search(position, 3, e)
+	next, _ := position.Play(result.Move)
+	if !strings.Contains(got, "Output move: "+result.Move.String()+"\n") || !strings.Contains(got, "Output position: "+next.String()+"\n") {
+		t.Errorf("got %q, want move %s to %s", got, result.Move, next)
+	}
+	if written, _ := os.ReadFile(output); string(written) != next.String() {
+		t.Errorf("wrote %q, want %q", written, next)
+	}
+}
+
+// Test that the minimax and ab algorithms search an extended position in its own phase, whatever --phase
+// says, and that the legacy binaries reject it outside theirs
+func TestSearchExtended(t *testing.T) {
+	input := writeInput(t, "WWWxBBxxxxxxxxxxxxxxx W 0 0 0 20")
+	position, _ := representation.ParsePosition("WWWxBBxxxxxxxxxxxxxxx W 0 0 0 20")
+	e, _ := representation.LookupEvaluator(representation.DefaultEvaluator)
+	for _, algo := range []string{AlgoMiniMax, AlgoAB} {
+		got, err := run(t, "morris", "search", "--algo", algo, "--depth", "2", input)
+		if err != nil {
+			t.Fatalf("%s: %v", algo, err)
+		}
+		best, _, _ := representation.LegacyMiniMaxAB(&position.Board, representation.White, 2, representation.MidgameEndgame, e)
+		move := representation.MoveBetween(&position.Board, best, representation.White)
+		next, err := position.Play(move)
+		if err != nil || !strings.Contains(got, "Output move: "+move.String()+"\n") || !strings.Contains(got, "Output position: "+next.String()+"\n") {
+			t.Errorf("%s: got %q, want move %s to %s", algo, got, move, next)
+		}
+	}
+
+	if _, err := run(t, "morris", "legacy", "MiniMaxOpening", input, filepath.Join(t.TempDir(), "output.txt"), "2"); err == nil ||
+		!strings.Contains(err.Error(), "past the opening") {
+		t.Errorf("MiniMaxOpening on a midgame position: %v", err)
+	}
+	if _, err := run(t, "morris", "search", "--algo", "ab", writeInput(t, "WWWxBBxxxxxxxxxxxxxxx W 0 1 0 10")); err == nil {
+		t.Error("no error searching a midgame side against pieces in hand")
+	}
+}
+
+// Test that perft counts as the Perft tool does and needs the side and phase of a legacy board
+func TestPerftCommand(t *testing.T) {
+	input := writeInput(t, "xBxBWxxxxBxBWWxWWWBBB")
+	got, err := run(t, "morris", "perft", "--depth", "3", "--side", "W", "--phase", "midgame", input)
+	if err != nil || !strings.HasSuffix(got, "Nodes searched: 810\n") {
+		t.Errorf("got %q, %v", got, err)
+	}
+	if _, err := run(t, "morris", "perft", "--depth", "3", input); err == nil {
+		t.Error("no error without --side and --phase")
+	}
+}
+
+// Test that an engine game is played to its end and recorded
+func TestPlayCommand(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "game.txt")
+	got, err := run(t, "morris", "play", "--depth", "1", "--max-plies", "30", "--out", output)
+	if err != nil || !strings.HasSuffix(got, "Result: 1/2-1/2\n") {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	games, err := representation.ReadGameFile(output)
+	if err != nil || len(games) != 1 || len(games[0].Moves) != 30 {
+		t.Errorf("game record: %v, %v", games, err)
+	}
+}
+
+// Test the command dispatch
+func TestCommands(t *testing.T) {
+	if _, err := run(t, "morris"); err == nil || !strings.HasPrefix(err.Error(), "usage: morris") {
+		t.Errorf("no command: %v", err)
+	}
+	if _, err := run(t, "morris", "serach"); err == nil || !strings.Contains(err.Error(), `unknown command "serach"`) {
+		t.Errorf("unknown command: %v", err)
+	}
+	if _, err := run(t, "morris", "search", "--depth", "0", "x"); err == nil || err.Error() != "invalid depth: 0" {
+		t.Errorf("depth 0: %v", err)
+	}
+	got, err := run(t, "morris", "bench", "--depth", "2", "--positions", "2")
+	if err != nil || !strings.Contains(got, "Nodes/second: ") {
+		t.Errorf("bench: %q, %v", got, err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"representation"
+	"strings"
+)
+
+func runPerftCommand(args []string, out io.Writer) error {
+	usage := fmt.Errorf("usage: morris perft [--side W|B] [--phase opening|midgame] [--depth N] [--divide] [--distinct] <input_file>")
+	flags := newFlagSet("perft")
+	sideName := flags.String("side", "", "side to move of a legacy board")
+	phaseName := flags.String("phase", "", "phase of a legacy board")
+	depth := flags.Int("depth", 1, "depth of the tree")
+	divide := flags.Bool("divide", false, "break the count down per root move")
+	distinct := flags.Bool("distinct", false, "count distinct positions, before and after merging symmetric ones")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if len(positional) != 1 {
+		return usage
+	}
+	if *depth < 0 {
+		return fmt.Errorf("invalid depth: %d", *depth)
+	}
+	color, err := parseSide(*sideName)
+	if err != nil {
+		return err
+	}
+	phase, err := parsePhase(*phaseName)
+	if err != nil {
+		return err
+	}
+
+	input, err := os.ReadFile(positional[0])
+	if err != nil {
+		return fmt.Errorf("failed to read input board file: %v", err)
+	}
+	fmt.Fprintf(out, "Input position: %s\n", strings.TrimSpace(string(input)))
+
+	// An extended position is counted with the pieces in hand, a legacy board with the given side and phase
+	var entries []representation.PerftEntry
+	var nodes, positions, canonical int
+	if representation.IsExtendedPosition(string(input)) {
+		position, moves, err := representation.ParsePositionAndMoves(string(input), representation.White, representation.Opening)
+		if err != nil {
+			return fmt.Errorf("invalid input position %s: %v", positional[0], err)
+		}
+		if len(moves) > 0 {
+			fmt.Fprintf(out, "Position after moves: %s\n", position.String())
+		}
+		if *distinct {
+			positions, canonical = representation.CountDistinctPosition(position, *depth)
+		}
+		if *divide && *depth > 0 {
+			entries = representation.PerftPositionDivide(position, *depth)
+		} else {
+			nodes = representation.PerftPosition(position, *depth)
+		}
+	} else {
+		if color == 0 || phase == -1 {
+			return fmt.Errorf("a legacy board needs --side W|B and --phase opening|midgame")
+		}
+		board, moves, err := representation.ParseBoardAndMoves(string(input), color, phase)
+		if err != nil {
+			return fmt.Errorf("invalid input board %s: %v", positional[0], err)
+		}
+		if len(moves) > 0 {
+			fmt.Fprintf(out, "Position after moves: %s\n", board.String())
+		}
+		if *distinct {
+			positions, canonical = representation.CountDistinct(board, color, *depth, phase)
+		}
+		if *divide && *depth > 0 {
+			entries = representation.PerftDivide(board, color, *depth, phase)
+		} else {
+			nodes = representation.Perft(board, color, *depth, phase)
+		}
+	}
+
+	for _, entry := range entries {
+		fmt.Fprintf(out, "%s %s: %d\n", entry.Move.String(), entry.Board.String(), entry.Nodes)
+		nodes += entry.Nodes
+	}
+	fmt.Fprintf(out, "Nodes searched: %d\n", nodes)
+	if *distinct {
+		fmt.Fprintf(out, "Distinct positions: %d\n", positions)
+		fmt.Fprintf(out, "Distinct positions after symmetry reduction (%d symmetries): %d\n", len(representation.Symmetries()), canonical)
+	}
+	return nil
+}
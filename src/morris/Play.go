@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"representation"
+)
+
+func runPlayCommand(args []string, out io.Writer) error {
+	usage := fmt.Errorf("usage: morris play [--human W|B] [--side W|B] [--phase opening|midgame] [--depth N] [--eval NAME | --weights FILE] [--max-plies N] [--out FILE] [input_file]")
+	evaluator, args, err := representation.ParseEvaluatorFlag(args)
+	if err != nil {
+		return err
+	}
+	flags := newFlagSet("play")
+	humanName := flags.String("human", "", "side played from the terminal, the engine playing both if empty")
+	sideName := flags.String("side", "W", "side to move of a legacy board")
+	phaseName := flags.String("phase", "opening", "phase of a legacy board")
+	depth := flags.Int("depth", 3, "search depth")
+	maxPlies := flags.Int("max-plies", 200, "plies after which the game is drawn")
+	output := flags.String("out", "", "game record file to write at the end of the game")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if len(positional) > 1 || *depth < 1 {
+		return usage
+	}
+	side, err := parseSide(*sideName)
+	if err != nil {
+		return err
+	}
+	phase, err := parsePhase(*phaseName)
+	if err != nil {
+		return err
+	}
+	if side == 0 || phase == -1 {
+		return usage
+	}
+	human, err := parseSide(*humanName)
+	if err != nil {
+		return err
+	}
+
+	// The game starts from the input file if there is one, otherwise from the empty board
+	start := representation.StartPosition()
+	if len(positional) == 1 {
+		if start, _, err = representation.ReadLegacyPosition(out, positional[0], side, phase); err != nil {
+			return err
+		}
+	}
+
+	engine := representation.Engine{Name: fmt.Sprintf("morris depth %d", *depth), Evaluator: evaluator, Depth: *depth}
+	var g *representation.Game
+	if human != 0 {
+		fmt.Fprintf(out, "You play %s; type help for the commands\n", colorName(human))
+		s := newSession(os.Stdin, out, start, human, engine)
+		if err := s.run(); err != nil {
+			return err
+		}
+		g = s.game()
+	} else {
+		g = representation.PlayGame(engine, engine, start, *maxPlies)
+		p := start
+		for ply, m := range g.Moves {
+			fmt.Fprintf(out, "%3d %-5s %-9s %d\n", ply+1, colorName(p.SideToMove), m.Move.String(), m.Eval)
+			p, _ = p.Play(m.Move)
+		}
+		fmt.Fprintf(out, "Result: %s\n", g.Result)
+	}
+
+	if *output == "" {
+		return nil
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to write game record: %v", err)
+	}
+	if err := representation.NewGameWriter(f).Write(g); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func colorName(color int) string {
+	if color == representation.Black {
+		return "Black"
+	}
+	return "White"
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"representation"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- Engine protocol
+//
+// A line based protocol modeled on UCI, for GUIs and referees to drive the engine over stdin and stdout. The
+// engine keeps its position and options between commands; a search runs in the background, streaming info
+// lines, so that stop, isready and quit are answered while it thinks. Moves are in square notation.
+
+const protocolHelp = `Commands:
+  uci                                    identify the engine and list its options, then uciok
+  isready                                readyok once the commands before are done
+  setoption name NAME [value VALUE]      set an option
+  ucinewgame                             forget the game, back to the start position
+  position startpos [moves M...]         set up the start position and play moves from it
+  position extended POSITION [moves M...] set up an extended position, such as xxxxxxWxxBxxxxxxBxWxx B 7 7 0 3
+  go [depth N] [movetime MS] [infinite]  search, then print bestmove; infinite waits for stop
+  stop                                   end the search and print bestmove
+  d                                      show the position
+  quit                                   leave
+`
+
+// protocol is the state of the engine between commands
+type protocol struct {
+	in       *bufio.Scanner
+	out      io.Writer
+	outMu    sync.Mutex // Search info lines and command replies are written from different goroutines
+	position representation.Position
+	evalName string
+	weights  string
+	algo     string
+
+	evaluator representation.Evaluator
+	stop      chan struct{} // Closed to stop the running search, nil if there is none
+	done      chan struct{} // Closed once the running search printed bestmove
+}
+
+func newProtocol(in io.Reader, out io.Writer) *protocol {
+	evaluator, _ := representation.LookupEvaluator(representation.DefaultEvaluator)
+	return &protocol{
+		in:        bufio.NewScanner(in),
+		out:       out,
+		position:  representation.StartPosition(),
+		evalName:  representation.DefaultEvaluator,
+		algo:      representation.AlgorithmAlphaBeta,
+		evaluator: evaluator,
+	}
+}
+
+// send writes a line of output
+func (pr *protocol) send(format string, args ...any) {
+	pr.outMu.Lock()
+	defer pr.outMu.Unlock()
+	fmt.Fprintf(pr.out, format+"\n", args...)
+}
+
+// run answers commands until quit or the end of the input, then stops the search
+func (pr *protocol) run() error {
+	for pr.in.Scan() {
+		fields := strings.Fields(pr.in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" {
+			break
+		}
+		if err := pr.handle(fields[0], fields[1:]); err != nil {
+			pr.send("info string %v", err)
+		}
+	}
+	pr.stopSearch()
+	return pr.in.Err()
+}
+
+func (pr *protocol) handle(cmd string, args []string) error {
+	if pr.searching() {
+		switch cmd {
+		case "isready", "stop", "d", "help", "uci":
+		default:
+			return fmt.Errorf("%s ignored while searching", cmd)
+		}
+	}
+	switch cmd {
+	case "uci":
+		pr.send("id name morris")
+		pr.send("id author NineMensMorrisAI")
+		pr.send("option name Evaluator type combo default %s%s", representation.DefaultEvaluator, " var "+strings.Join(representation.EvaluatorNames(), " var "))
+		pr.send("option name Weights type string default <empty>")
+		pr.send("option name Algorithm type combo default %s var %s var %s", representation.AlgorithmAlphaBeta, representation.AlgorithmAlphaBeta, representation.AlgorithmMiniMax)
+		pr.send("uciok")
+	case "isready":
+		pr.send("readyok")
+	case "setoption":
+		return pr.setOption(args)
+	case "ucinewgame":
+		pr.position = representation.StartPosition()
+	case "position":
+		return pr.setPosition(args)
+	case "go":
+		return pr.goSearch(args)
+	case "stop":
+		pr.stopSearch()
+	case "d":
+		pr.send("%s%s", boardDiagram(&pr.position.Board), pr.position.String())
+	case "help":
+		pr.send("%s", strings.TrimSuffix(protocolHelp, "\n"))
+	default:
+		return fmt.Errorf("unknown command %s", cmd)
+	}
+	return nil
+}
+
+// setOption reads "name NAME [value VALUE]", names and values being able to hold spaces
+func (pr *protocol) setOption(args []string) error {
+	line := " " + strings.Join(args, " ")
+	name, value, _ := strings.Cut(strings.TrimPrefix(line, " name "), " value ")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if !strings.HasPrefix(line, " name ") || name == "" {
+		return fmt.Errorf("usage: setoption name NAME [value VALUE]")
+	}
+
+	evalName, weights := pr.evalName, pr.weights
+	switch strings.ToLower(name) {
+	case "evaluator":
+		evalName = value
+	case "weights":
+		if value == "<empty>" {
+			value = ""
+		}
+		weights = value
+	case "algorithm":
+		if value != representation.AlgorithmAlphaBeta && value != representation.AlgorithmMiniMax {
+			return fmt.Errorf("invalid algorithm %s", value)
+		}
+		pr.algo = value
+		return nil
+	default:
+		return fmt.Errorf("unknown option %s", name)
+	}
+	args = []string{"--eval", evalName}
+	if weights != "" {
+		args = append(args, "--weights", weights)
+	}
+	evaluator, _, err := representation.ParseEvaluatorFlag(args)
+	if err != nil {
+		return err
+	}
+	pr.evaluator, pr.evalName, pr.weights = evaluator, evalName, weights
+	return nil
+}
+
+// setPosition reads "startpos" or "extended" and the six fields of an extended position, then the moves
+// played from it
+func (pr *protocol) setPosition(args []string) error {
+	var p representation.Position
+	switch {
+	case len(args) >= 1 && args[0] == "startpos":
+		p, args = representation.StartPosition(), args[1:]
+	case len(args) >= 7 && args[0] == "extended":
+		var err error
+		if p, err = representation.ParsePosition(strings.Join(args[1:7], " ")); err != nil {
+			return err
+		}
+		args = args[7:]
+	default:
+		return fmt.Errorf("usage: position startpos|extended POSITION [moves M...]")
+	}
+	if len(args) > 0 && args[0] != "moves" {
+		return fmt.Errorf("expected moves, found %s", args[0])
+	}
+	for i := 1; i < len(args); i++ {
+		m, err := representation.ParseMove(args[i])
+		if err != nil {
+			return err
+		}
+		if p, err = p.Play(m); err != nil {
+			return fmt.Errorf("illegal move %s in position %s", args[i], p.String())
+		}
+	}
+	pr.position = p
+	return nil
+}
+
+// goSearch starts a search of the position in the background. Without depth and movetime it runs until stop,
+// as with infinite, which also holds back bestmove until stop even if the search ends first.
+func (pr *protocol) goSearch(args []string) error {
+	limits := representation.Limits{MiniMax: pr.algo == representation.AlgorithmMiniMax}
+	infinite := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "infinite":
+			infinite = true
+			continue
+		case "depth", "movetime":
+		default:
+			return fmt.Errorf("unknown go option %s", args[i])
+		}
+		if i+1 == len(args) {
+			return fmt.Errorf("go %s needs a value", args[i])
+		}
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid go %s %s", args[i], args[i+1])
+		}
+		if args[i] == "depth" {
+			limits.Depth = n
+		} else {
+			limits.MoveTime = time.Duration(n) * time.Millisecond
+		}
+		i++
+	}
+	if infinite {
+		limits.Depth, limits.MoveTime = 0, 0
+	}
+	infinite = limits.Depth == 0 && limits.MoveTime == 0
+
+	p, start := pr.position, time.Now()
+	stop, done := make(chan struct{}), make(chan struct{})
+	pr.stop, pr.done = stop, done
+	limits.Stop = stop
+	limits.Info = func(r representation.SearchResult) {
+		elapsed := time.Since(start)
+		pr.send("info depth %d score %s nodes %d time %d nps %d pv %s", r.Depth, protocolScore(r.Estimate, p.SideToMove), r.Nodes,
+			elapsed.Milliseconds(), int(float64(r.Nodes)/max(elapsed.Seconds(), 1e-3)), formatMoves(r.PV))
+	}
+	go func() {
+		defer close(done)
+		r := representation.SearchLimits(p, pr.evaluator, limits)
+		if infinite {
+			<-stop
+		}
+		if r.Move.To == representation.NoSquare {
+			pr.send("bestmove (none)")
+		} else if len(r.PV) > 1 {
+			pr.send("bestmove %s ponder %s", r.Move.String(), r.PV[1].String())
+		} else {
+			pr.send("bestmove %s", r.Move.String())
+		}
+	}()
+	return nil
+}
+
+// searching reports whether a search has yet to print bestmove
+func (pr *protocol) searching() bool {
+	if pr.done == nil {
+		return false
+	}
+	select {
+	case <-pr.done:
+		pr.stop, pr.done = nil, nil
+		return false
+	default:
+		return true
+	}
+}
+
+// stopSearch stops the running search, if any, and waits for its bestmove
+func (pr *protocol) stopSearch() {
+	if pr.done == nil {
+		return
+	}
+	select {
+	case <-pr.stop:
+	default:
+		close(pr.stop)
+	}
+	<-pr.done
+	pr.stop, pr.done = nil, nil
+}
+
+// protocolScore gives an estimate from the point of view of the side to move, as "cp N" or, for a won or
+// lost game, as "mate N" with N the moves to the end, negative if the side to move loses
+func protocolScore(estimate int, sideToMove int) string {
+	if sideToMove == representation.Black {
+		estimate = -estimate
+	}
+	switch {
+	case estimate > representation.WinEstimate-1000:
+		return fmt.Sprintf("mate %d", (representation.WinEstimate-estimate+1)/2)
+	case estimate < -representation.WinEstimate+1000:
+		return fmt.Sprintf("mate %d", -(representation.WinEstimate+estimate+1)/2)
+	}
+	return fmt.Sprintf("cp %d", estimate)
+}
+
+func runProtocolCommand(args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: morris uci\n%s", strings.TrimSuffix(protocolHelp, "\n"))
+	}
+	return newProtocol(os.Stdin, out).run()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"representation"
+	"strings"
+	"testing"
+)
+
+// Test the handshake and that options change the evaluator
+func TestProtocolHandshakeAndOptions(t *testing.T) {
+	var out bytes.Buffer
+	pr := newProtocol(strings.NewReader("uci\nsetoption name Evaluator value tapered\nsetoption name Algorithm value minimax\nsetoption name Evaluator value none\nisready\nquit\ngo\n"), &out)
+	if err := pr.run(); err != nil {
+		t.Fatal(err)
+	}
+	want := "id name morris\n"
+	if got := out.String(); !strings.HasPrefix(got, want) || !strings.Contains(got, "var tapered") || !strings.Contains(got, "uciok\n") ||
+		!strings.Contains(got, "info string unknown evaluator") || !strings.HasSuffix(got, "readyok\n") {
+		t.Errorf("output:\n%s", got)
+	}
+	if pr.evalName != "tapered" || pr.algo != representation.AlgorithmMiniMax {
+		t.Errorf("evaluator %s, algorithm %s", pr.evalName, pr.algo)
+	}
+}
+
+// Test that the position is kept between searches, that a search streams info lines and ends with bestmove,
+// and that an infinite search waits for stop
+func TestProtocolSearch(t *testing.T) {
+	var out bytes.Buffer
+	pr := newProtocol(strings.NewReader(""), &out)
+	if err := pr.handle("position", strings.Fields("startpos moves a0 g6")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pr.handle("position", strings.Fields("startpos moves a0 a0")); err == nil {
+		t.Error("illegal move accepted")
+	}
+	if err := pr.handle("go", strings.Fields("depth 3")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pr.handle("position", strings.Fields("startpos")); err == nil {
+		t.Error("position changed while searching")
+	}
+	<-pr.done
+	got := out.String()
+	if !strings.Contains(got, "info depth 1 score cp ") || !strings.Contains(got, "info depth 3 ") || !strings.Contains(got, "\nbestmove ") {
+		t.Errorf("output:\n%s", got)
+	}
+	if pr.searching() || pr.position.String() != "WxxxxxxxxxxxxxxxxxxxB W 8 8 0 2" {
+		t.Errorf("position %s after the search", pr.position)
+	}
+
+	out.Reset()
+	pr.handle("position", strings.Fields("extended WxWxxxxxxxxxxxxxxxBBB W 1 0 0 10"))
+	pr.handle("go", strings.Fields("infinite"))
+	if !pr.searching() {
+		t.Fatal("infinite search of a won position ended before stop")
+	}
+	pr.handle("stop", nil)
+	if got := out.String(); !strings.Contains(got, "score mate 1 ") || !strings.HasSuffix(got, "bestmove c2xa6\n") {
+		t.Errorf("output:\n%s", got)
+	}
+}
+
+// Test the scores from the side to move
+func TestProtocolScore(t *testing.T) {
+	cases := []struct {
+		estimate, side int
+		want           string
+	}{
+		{25, representation.White, "cp 25"}, {25, representation.Black, "cp -25"},
+		{representation.WinEstimate - 3, representation.White, "mate 2"}, {representation.WinEstimate - 2, representation.Black, "mate -1"},
+	}
+	for _, c := range cases {
+		if got := protocolScore(c.estimate, c.side); got != c.want {
+			t.Errorf("protocolScore(%d, %d) = %s, want %s", c.estimate, c.side, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"representation"
+)
+
+// Search algorithms of --algo
+const (
+	AlgoMiniMax = "minimax" // Plain minimax of the legacy binaries, every ply in one phase
+	AlgoAB      = "ab"      // Alpha-beta of the legacy binaries, every ply in one phase
+	AlgoFull    = "full"    // Alpha-beta over extended positions, across the phases
+)
+
+// searchOptions are the settings of a search command
+type searchOptions struct {
+	Input     string // Position file
+	Output    string // File the output position is written to, none if empty
+	Side      int    // Side to move of a legacy board
+	Phase     int    // Phase of a legacy board, and of every ply of the minimax and ab algorithms
+	Algo      string
+	Depth     int
+	Evaluator representation.Evaluator
+	Explain   bool
+}
+
+// runSearch searches the position of the input file and prints the best move as the legacy binaries do.
+// The minimax and ab algorithms are those of the legacy binaries, except that an extended position is
+// searched in its own phase.
+func runSearch(out io.Writer, o searchOptions) error {
+	if o.Algo == AlgoMiniMax || o.Algo == AlgoAB {
+		return representation.LegacySearch{
+			Input: o.Input, Output: o.Output, Side: o.Side, Phase: o.Phase, OwnPhase: true,
+			AlphaBeta: o.Algo == AlgoAB, Depth: o.Depth, Evaluator: o.Evaluator, Explain: o.Explain,
+		}.Run(out)
+	}
+	if o.Algo != AlgoFull {
+		return fmt.Errorf("invalid algorithm: %s", o.Algo)
+	}
+
+	position, extended, err := representation.ReadLegacyPosition(out, o.Input, o.Side, o.Phase)
+	if err != nil {
+		return err
+	}
+	result := representation.Search(position, o.Depth, o.Evaluator)
+	best := &position.Board // Board after the best move
+	if next, err := position.Play(result.Move); err == nil {
+		best = &next.Board
+	}
+	output, err := representation.LegacyOutput(position, extended, best, result.Move)
+	if err != nil {
+		return err
+	}
+
+	// Break the evaluation down at the root and at the leaf of the principal variation
+	var explanation string
+	if o.Explain {
+		var line []*representation.MorrisBoard
+		p := position
+		for _, m := range result.PV {
+			p, _ = p.Play(m)
+			leaf := p.Board
+			line = append(line, &leaf)
+		}
+		explanation = representation.ExplainLine(o.Evaluator, &position.Board, position.SideToMove, position.Phase(), line)
+	}
+	return representation.WriteLegacyResult(out, o.Output, output, result.Move, result.Nodes, result.Estimate, explanation)
+}
+
+func runSearchCommand(args []string, out io.Writer) error {
+	usage := fmt.Errorf("usage: morris search [--side W|B] [--phase opening|midgame] [--algo minimax|ab|full] [--depth N] [--eval NAME | --weights FILE] [--explain] [--out FILE] <input_file>")
+	evaluator, args, err := representation.ParseEvaluatorFlag(args)
+	if err != nil {
+		return err
+	}
+	flags := newFlagSet("search")
+	sideName := flags.String("side", "W", "side to move of a legacy board")
+	phaseName := flags.String("phase", "opening", "phase of a legacy board")
+	algo := flags.String("algo", AlgoAB, "search algorithm")
+	depth := flags.Int("depth", 3, "search depth")
+	explain := flags.Bool("explain", false, "break the evaluation down at the root and the PV leaf")
+	output := flags.String("out", "", "file to write the output position to")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if len(positional) != 1 {
+		return usage
+	}
+	side, err := parseSide(*sideName)
+	if err != nil {
+		return err
+	}
+	phase, err := parsePhase(*phaseName)
+	if err != nil {
+		return err
+	}
+	if *depth < 1 {
+		return fmt.Errorf("invalid depth: %d", *depth)
+	}
+	if side == 0 || phase == -1 {
+		return usage
+	}
+
+	return runSearch(out, searchOptions{
+		Input: positional[0], Output: *output, Side: side, Phase: phase,
+		Algo: *algo, Depth: *depth, Evaluator: evaluator, Explain: *explain,
+	})
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"representation"
+	"runtime"
+	"time"
+)
+
+// --- HTTP analysis server
+//
+// Every endpoint takes a POST of a JSON apiRequest and answers with JSON, an apiError and a 4xx or 5xx status
+// if it fails. Searches and perft counts run on a bounded number of workers and stop once the request times
+// out or its client goes away.
+
+// apiRequest is the body of every request, each endpoint reading the fields it needs
+type apiRequest struct {
+	Position string // Extended position or legacy board, either followed by moves; the start position if empty
+	Side     string // Side to move of a legacy board, W by default
+	Phase    string // Phase of a legacy board, opening by default
+	Eval     string // Registered evaluator, the server's if empty
+	Algo     string // Search algorithm, ab or minimax
+	Depth    int    // Search or perft depth
+	MoveTime int    // Search time in milliseconds
+	Divide   bool   // Break the perft count down per root move
+}
+
+type apiError struct {
+	Error string
+}
+
+type validateResponse struct {
+	Valid      bool
+	Error      string `json:",omitempty"`
+	Position   string `json:",omitempty"` // Extended position after the moves
+	SideToMove string `json:",omitempty"`
+	Phase      string `json:",omitempty"`
+	Outcome    string `json:",omitempty"`
+}
+
+type movesResponse struct {
+	Position string
+	Moves    []string
+}
+
+type evalResponse struct {
+	Position    string
+	Estimate    int // What a search of depth 0 returns, White's point of view
+	Explanation representation.Explanation
+}
+
+type searchResponse struct {
+	Position string
+	Move     string // Empty if the game is over
+	Estimate int    // White's point of view
+	Score    string // Side to move's point of view, as in the engine protocol
+	Depth    int
+	Nodes    int
+	PV       []string
+	Time     int // Milliseconds
+}
+
+type perftResponse struct {
+	Position string
+	Depth    int
+	Nodes    int
+	Divide   []perftEntry `json:",omitempty"`
+}
+
+type perftEntry struct {
+	Move  string
+	Nodes int
+}
+
+// server holds the settings and the search workers of the analysis server
+type server struct {
+	evaluator representation.Evaluator // Used unless a request names one
+	timeout   time.Duration
+	maxDepth  int
+	workers   chan struct{} // Holds a token for every running search or perft count
+}
+
+func newServer(evaluator representation.Evaluator, workers int, timeout time.Duration, maxDepth int) *server {
+	return &server{evaluator: evaluator, timeout: timeout, maxDepth: maxDepth, workers: make(chan struct{}, max(workers, 1))}
+}
+
+// handler routes the endpoints of the API
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/validate", s.api(s.validate))
+	mux.HandleFunc("POST /api/moves", s.api(s.moves))
+	mux.HandleFunc("POST /api/eval", s.api(s.eval))
+	mux.HandleFunc("POST /api/search", s.api(s.search))
+	mux.HandleFunc("POST /api/perft", s.api(s.perft))
+	return mux
+}
+
+// httpError is an error with the status to answer it with
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string { return e.err.Error() }
+
+func badRequest(format string, args ...any) error {
+	return &httpError{http.StatusBadRequest, fmt.Errorf(format, args...)}
+}
+
+// api decodes the request, runs an endpoint with the request's context bounded by the timeout, and encodes
+// its response or error
+func (s *server) api(endpoint func(ctx context.Context, req apiRequest) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+		defer cancel()
+
+		var req apiRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+		decoder.DisallowUnknownFields()
+		response, err := any(nil), decoder.Decode(&req)
+		if err != nil && !errors.Is(err, io.EOF) { // An empty body asks about the start position
+			err = badRequest("invalid request: %v", err)
+		} else {
+			response, err = endpoint(ctx, req)
+		}
+
+		status := http.StatusOK
+		if err != nil {
+			var he *httpError
+			switch {
+			case errors.As(err, &he):
+				status = he.status
+			case r.Context().Err() != nil:
+				return // The client went away, nobody reads the answer
+			case errors.Is(ctx.Err(), context.DeadlineExceeded):
+				status, err = http.StatusGatewayTimeout, fmt.Errorf("timed out after %v", s.timeout)
+			default:
+				status = http.StatusInternalServerError
+			}
+			response = apiError{err.Error()}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// position reads the position of a request, played through its moves
+func (s *server) position(req apiRequest) (representation.Position, error) {
+	if req.Position == "" {
+		return representation.StartPosition(), nil
+	}
+	side, err := parseSide(req.Side)
+	if err != nil {
+		return representation.Position{}, badRequest("%v", err)
+	}
+	phase, err := parsePhase(req.Phase)
+	if err != nil {
+		return representation.Position{}, badRequest("%v", err)
+	}
+	if side == 0 {
+		side = representation.White
+	}
+	if phase == -1 {
+		phase = representation.Opening
+	}
+	p, _, err := representation.ParsePositionAndMoves(req.Position, side, phase)
+	if err != nil {
+		return representation.Position{}, badRequest("invalid position: %v", err)
+	}
+	return p, nil
+}
+
+// evaluatorOf returns the evaluator a request names, the server's if none
+func (s *server) evaluatorOf(req apiRequest) (representation.Evaluator, error) {
+	if req.Eval == "" {
+		return s.evaluator, nil
+	}
+	e, err := representation.LookupEvaluator(req.Eval)
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+	return e, nil
+}
+
+// acquire waits for a free worker until ctx is done, and returns the function that frees it
+func (s *server) acquire(ctx context.Context) (func(), error) {
+	select {
+	case s.workers <- struct{}{}:
+		return func() { <-s.workers }, nil
+	case <-ctx.Done():
+		return nil, &httpError{http.StatusServiceUnavailable, fmt.Errorf("all %d workers busy", cap(s.workers))}
+	}
+}
+
+func (s *server) validate(ctx context.Context, req apiRequest) (any, error) {
+	p, err := s.position(req)
+	if err != nil {
+		return validateResponse{Error: err.Error()}, nil
+	}
+	phase := "opening"
+	if p.Phase() == representation.MidgameEndgame {
+		phase = "midgame"
+	}
+	return validateResponse{Valid: true, Position: p.String(), SideToMove: colorName(p.SideToMove), Phase: phase, Outcome: p.Outcome()}, nil
+}
+
+func (s *server) moves(ctx context.Context, req apiRequest) (any, error) {
+	p, err := s.position(req)
+	if err != nil {
+		return nil, err
+	}
+	response := movesResponse{Position: p.String(), Moves: []string{}}
+	if p.Outcome() == representation.Unfinished {
+		response.Moves = moveStrings(p.LegalMoves())
+	}
+	return response, nil
+}
+
+func (s *server) eval(ctx context.Context, req apiRequest) (any, error) {
+	p, err := s.position(req)
+	if err != nil {
+		return nil, err
+	}
+	e, err := s.evaluatorOf(req)
+	if err != nil {
+		return nil, err
+	}
+	return evalResponse{
+		Position:    p.String(),
+		Estimate:    representation.Search(p, 0, e).Estimate,
+		Explanation: representation.ExplainEvaluation(e, &p.Board, p.Phase(), p.SideToMove),
+	}, nil
+}
+
+func (s *server) search(ctx context.Context, req apiRequest) (any, error) {
+	return s.searchStreaming(ctx, req, nil)
+}
+
+// searchStreaming runs the search of a request, calling info, if not nil, after every depth
+func (s *server) searchStreaming(ctx context.Context, req apiRequest, info func(r representation.SearchResult, p representation.Position, elapsed time.Duration)) (any, error) {
+	p, err := s.position(req)
+	if err != nil {
+		return nil, err
+	}
+	e, err := s.evaluatorOf(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case req.Depth < 0 || req.Depth > s.maxDepth:
+		return nil, badRequest("depth %d out of 0..%d", req.Depth, s.maxDepth)
+	case req.MoveTime < 0:
+		return nil, badRequest("negative move time")
+	case req.Algo != "" && req.Algo != representation.AlgorithmAlphaBeta && req.Algo != representation.AlgorithmMiniMax:
+		return nil, badRequest("invalid algorithm %s", req.Algo)
+	}
+	limits := representation.Limits{Depth: req.Depth, MoveTime: time.Duration(req.MoveTime) * time.Millisecond, MiniMax: req.Algo == representation.AlgorithmMiniMax, Stop: ctx.Done()}
+	if limits.Depth == 0 && limits.MoveTime == 0 {
+		limits.Depth = min(3, s.maxDepth)
+	} else if limits.Depth == 0 {
+		limits.Depth = s.maxDepth // Timed searches deepen no further than the server allows
+	}
+
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	start := time.Now()
+	if info != nil {
+		limits.Info = func(r representation.SearchResult) { info(r, p, time.Since(start)) }
+	}
+	r := representation.SearchLimits(p, e, limits)
+	if ctx.Err() != nil {
+		return nil, ctx.Err() // Stopped before a depth or time limit
+	}
+
+	response := searchResponse{Position: p.String(), Estimate: r.Estimate, Score: protocolScore(r.Estimate, p.SideToMove), Depth: r.Depth, Nodes: r.Nodes,
+		PV: moveStrings(r.PV), Time: int(time.Since(start).Milliseconds())}
+	if r.Move.To != representation.NoSquare {
+		response.Move = r.Move.String()
+	}
+	return response, nil
+}
+
+// moveStrings returns moves in square notation, an empty list rather than nil for none
+func moveStrings(moves []representation.Move) []string {
+	names := []string{}
+	for _, m := range moves {
+		names = append(names, m.String())
+	}
+	return names
+}
+
+func (s *server) perft(ctx context.Context, req apiRequest) (any, error) {
+	p, err := s.position(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Depth < 0 || req.Depth > s.maxDepth {
+		return nil, badRequest("depth %d out of 0..%d", req.Depth, s.maxDepth)
+	}
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	response := perftResponse{Position: p.String(), Depth: req.Depth}
+	if !req.Divide || req.Depth == 0 {
+		response.Nodes, err = perftContext(ctx, p, req.Depth)
+		return response, err
+	}
+	for _, m := range p.LegalMoves() {
+		next, _ := p.Play(m)
+		nodes, err := perftContext(ctx, next, req.Depth-1)
+		if err != nil {
+			return nil, err
+		}
+		response.Nodes += nodes
+		response.Divide = append(response.Divide, perftEntry{m.String(), nodes})
+	}
+	return response, nil
+}
+
+// perftContext runs PerftPosition, looking whether ctx is done before every subtree deeper than 4 plies so
+// that a cancelled count stops soon
+func perftContext(ctx context.Context, p representation.Position, depth int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if depth <= 4 {
+		return representation.PerftPosition(p, depth), nil
+	}
+	nodes := 0
+	for _, m := range p.LegalMoves() {
+		next, _ := p.Play(m)
+		n, err := perftContext(ctx, next, depth-1)
+		if err != nil {
+			return 0, err
+		}
+		nodes += n
+	}
+	return nodes, nil
+}
+
+func runServeCommand(args []string, out io.Writer) error {
+	usage := fmt.Errorf("usage: morris serve [--addr HOST:PORT] [--workers N] [--timeout DURATION] [--max-depth N] [--eval NAME | --weights FILE]")
+	evaluator, args, err := representation.ParseEvaluatorFlag(args)
+	if err != nil {
+		return err
+	}
+	flags := newFlagSet("serve")
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	workers := flags.Int("workers", runtime.NumCPU(), "searches and perft counts run at the same time")
+	timeout := flags.Duration("timeout", 30*time.Second, "time after which a request is cancelled")
+	maxDepth := flags.Int("max-depth", 8, "deepest search or perft count a request may ask for")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if len(positional) != 0 || *workers < 1 || *timeout <= 0 || *maxDepth < 1 {
+		return usage
+	}
+
+	s := newServer(evaluator, *workers, *timeout, *maxDepth)
+	fmt.Fprintf(out, "Serving the analysis API on http://%s/api/\n", *addr)
+	return http.ListenAndServe(*addr, s.handler())
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"representation"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, workers int, timeout time.Duration) (*server, *httptest.Server) {
+	t.Helper()
+	e, _ := representation.LookupEvaluator(representation.DefaultEvaluator)
+	s := newServer(e, workers, timeout, 20)
+	ts := httptest.NewServer(s.handler())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// post sends a request to an endpoint and decodes the response into v, returning the status
+func post(t *testing.T, ctx context.Context, url string, body string, v any) int {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBufferString(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("%s: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+// Test every endpoint on valid and invalid requests
+func TestServeEndpoints(t *testing.T) {
+	_, ts := newTestServer(t, 2, 10*time.Second)
+	ctx := context.Background()
+
+	var v validateResponse
+	if status := post(t, ctx, ts.URL+"/api/validate", `{"Position": "xxxxxxxxxxxxxxxxxxxxx a0 g6", "Side": "B"}`, &v); status != 200 || !v.Valid || v.Position != "BxxxxxxxxxxxxxxxxxxxW B 8 8 0 2" {
+		t.Errorf("validate: %d %+v", status, v)
+	}
+	if post(t, ctx, ts.URL+"/api/validate", `{"Position": "xxx W 9 9 0 1"}`, &v); v.Valid || v.Error == "" {
+		t.Errorf("invalid position validated: %+v", v)
+	}
+
+	var m movesResponse
+	if status := post(t, ctx, ts.URL+"/api/moves", ``, &m); status != 200 || len(m.Moves) != 21 || m.Moves[0] != "a0" {
+		t.Errorf("moves: %d %+v", status, m)
+	}
+
+	var e evalResponse
+	if status := post(t, ctx, ts.URL+"/api/eval", `{"Position": "WxWxxxxxxxxxxxxxxxBBB W 1 0 0 10", "Eval": "material"}`, &e); status != 200 || e.Estimate != -1 || e.Explanation.Total != -1 {
+		t.Errorf("eval: %d %+v", status, e)
+	}
+
+	var s searchResponse
+	if status := post(t, ctx, ts.URL+"/api/search", `{"Position": "WxWxxxxxxxxxxxxxxxBBB W 1 0 0 10", "Depth": 2}`, &s); status != 200 || s.Move != "c2xa6" || s.Score != "mate 1" || len(s.PV) != 1 {
+		t.Errorf("search: %d %+v", status, s)
+	}
+	if status := post(t, ctx, ts.URL+"/api/search", `{"MoveTime": 20, "Algo": "minimax"}`, &s); status != 200 || s.Move == "" || s.Depth < 1 {
+		t.Errorf("timed search: %d %+v", status, s)
+	}
+
+	var p perftResponse
+	if status := post(t, ctx, ts.URL+"/api/perft", `{"Depth": 2, "Divide": true}`, &p); status != 200 || p.Nodes != 420 || len(p.Divide) != 21 || p.Divide[0].Nodes != 20 {
+		t.Errorf("perft: %d %+v", status, p)
+	}
+
+	var apiErr apiError
+	for body, want := range map[string]int{`{"Depth": 21}`: 400, `{"Eval": "none"}`: 400, `{"Speed": 1}`: 400, `{"Side": "X", "Position": "xxxxxxxxxxxxxxxxxxxxx"}`: 400} {
+		if status := post(t, ctx, ts.URL+"/api/search", body, &apiErr); status != want || apiErr.Error == "" {
+			t.Errorf("%s: %d %+v, want %d", body, status, apiErr, want)
+		}
+	}
+	if resp, err := http.Get(ts.URL + "/api/search"); err != nil || resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET: %v %v", resp.Status, err)
+	}
+}
+
+// Test that requests wait for a free worker, and that searches stop after the timeout or once the client
+// disconnects, freeing their worker
+func TestServeWorkersAndCancellation(t *testing.T) {
+	s, ts := newTestServer(t, 1, 100*time.Millisecond)
+	var apiErr apiError
+	s.workers <- struct{}{} // The only worker is busy
+	if status := post(t, context.Background(), ts.URL+"/api/search", `{"Depth": 1}`, &apiErr); status != http.StatusServiceUnavailable {
+		t.Errorf("busy: %d %+v", status, apiErr)
+	}
+	<-s.workers
+
+	start := time.Now()
+	if status := post(t, context.Background(), ts.URL+"/api/search", `{"Depth": 20}`, &apiErr); status != http.StatusGatewayTimeout || time.Since(start) > 5*time.Second {
+		t.Errorf("timeout: %d %+v after %v", status, apiErr, time.Since(start))
+	}
+
+	s, ts = newTestServer(t, 1, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	post(t, ctx, ts.URL+"/api/perft", `{"Depth": 20}`, &apiErr)
+	for deadline := time.Now().Add(5 * time.Second); len(s.workers) > 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the worker of a disconnected client is still busy")
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package main
+
+import "fmt"
+
+// makeRaw is only implemented for Linux terminals
+func makeRaw(fd int) (func(), error) {
+	return nil, fmt.Errorf("the terminal UI needs a Linux terminal")
+}
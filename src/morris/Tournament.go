@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"representation"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Tournament pairings
+const (
+	RoundRobin = "round-robin" // Every engine against every other
+	Gauntlet   = "gauntlet"    // The first engine against every other
+)
+
+// readTournamentConfig reads the engines of a tournament, one engine spec per line, skipping blank lines and
+// comments starting with #
+func readTournamentConfig(path string) ([]representation.Engine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var engines []representation.Engine
+	names := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		spec := strings.TrimSpace(scanner.Text())
+		if spec == "" || strings.HasPrefix(spec, "#") {
+			continue
+		}
+		e, err := parseEngine(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		if names[e.Name] {
+			return nil, fmt.Errorf("%s:%d: engine name %q taken, give one with name=", path, line, e.Name)
+		}
+		names[e.Name] = true
+		engines = append(engines, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(engines) < 2 {
+		return nil, fmt.Errorf("%s: %d engines, a tournament needs at least 2", path, len(engines))
+	}
+	return engines, nil
+}
+
+// pairings returns the index pairs of the engines that meet
+func pairings(mode string, engines int) [][2]int {
+	var pairs [][2]int
+	for i := 0; i < engines; i++ {
+		for j := i + 1; j < engines; j++ {
+			if mode == RoundRobin || i == 0 {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	}
+	return pairs
+}
+
+// --- Crosstable
+
+// Crosstable is the result of a tournament, its engines ranked by points
+type Crosstable struct {
+	Mode            string
+	GamesPerPairing int
+	Engines         []CrosstableRow
+}
+
+// CrosstableRow holds the results of one engine of a crosstable
+type CrosstableRow struct {
+	Name      string
+	Elo       float64 // BayesElo of the tournament games
+	Margin    float64
+	Points    float64
+	Games     int
+	Opponents []CrosstableCell // In the order of the rows
+}
+
+// CrosstableCell holds the results of an engine against one opponent, no games if they did not meet
+type CrosstableCell struct {
+	Opponent string
+	Points   float64
+	Games    int
+}
+
+// newCrosstable counts the results of the games of a tournament
+func newCrosstable(mode string, gamesPerPairing int, engines []representation.Engine, results []representation.PairResult) *Crosstable {
+	ct := &Crosstable{Mode: mode, GamesPerPairing: gamesPerPairing}
+	row := map[string]int{}
+	for i, e := range engines {
+		row[e.Name] = i
+		ct.Engines = append(ct.Engines, CrosstableRow{Name: e.Name})
+	}
+	points := make([][]float64, len(engines))
+	games := make([][]int, len(engines))
+	for i := range engines {
+		points[i], games[i] = make([]float64, len(engines)), make([]int, len(engines))
+	}
+	for _, r := range results {
+		w, b := row[r.White], row[r.Black]
+		n := r.Wins + r.Draws + r.Losses
+		points[w][b] += float64(r.Wins) + float64(r.Draws)/2
+		points[b][w] += float64(r.Losses) + float64(r.Draws)/2
+		games[w][b] += n
+		games[b][w] += n
+	}
+	for _, rating := range representation.BayesElo(results) {
+		ct.Engines[row[rating.Name]].Elo, ct.Engines[row[rating.Name]].Margin = rating.Elo, rating.Margin
+	}
+
+	for i := range ct.Engines {
+		for j := range engines {
+			ct.Engines[i].Points += points[i][j]
+			ct.Engines[i].Games += games[i][j]
+		}
+	}
+	order := make([]int, len(engines))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return ct.Engines[order[a]].Points > ct.Engines[order[b]].Points })
+	rows := make([]CrosstableRow, len(order))
+	for rank, i := range order {
+		rows[rank] = ct.Engines[i]
+		for _, j := range order {
+			if j != i {
+				rows[rank].Opponents = append(rows[rank].Opponents, CrosstableCell{Opponent: engines[j].Name, Points: points[i][j], Games: games[i][j]})
+			} else {
+				rows[rank].Opponents = append(rows[rank].Opponents, CrosstableCell{Opponent: engines[j].Name})
+			}
+		}
+	}
+	ct.Engines = rows
+	return ct
+}
+
+// Write prints the crosstable as text, a column per opponent in the order of the rows
+func (ct *Crosstable) Write(w io.Writer) {
+	width := len("Engine")
+	for _, row := range ct.Engines {
+		width = max(width, len(row.Name))
+	}
+	fmt.Fprintf(w, "%3s  %-*s %7s %7s %7s %6s", "#", width, "Engine", "Elo", "+/-", "Points", "Games")
+	for i := range ct.Engines {
+		fmt.Fprintf(w, " %7d", i+1)
+	}
+	fmt.Fprintln(w)
+	for rank, row := range ct.Engines {
+		fmt.Fprintf(w, "%3d  %-*s %7.0f %7.0f %7g %6d", rank+1, width, row.Name, row.Elo, row.Margin, row.Points, row.Games)
+		for _, cell := range row.Opponents {
+			switch {
+			case cell.Opponent == row.Name:
+				fmt.Fprintf(w, " %7s", "---")
+			case cell.Games == 0:
+				fmt.Fprintf(w, " %7s", "")
+			default:
+				fmt.Fprintf(w, " %7s", fmt.Sprintf("%g/%d", cell.Points, cell.Games))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// --- Rating list
+
+// RatingList is the persistent rating list of the tournaments: the results of every pair of engines that
+// met, and the BayesElo ratings of all of them
+type RatingList struct {
+	Results []representation.PairResult
+	Ratings []representation.Rating
+}
+
+// LoadRatingList reads a rating list file, an empty list if there is none yet
+func LoadRatingList(path string) (*RatingList, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &RatingList{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rl := &RatingList{}
+	if err := json.Unmarshal(data, rl); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return rl, nil
+}
+
+// Add counts new results and rates all engines again
+func (rl *RatingList) Add(results []representation.PairResult) {
+	for _, r := range results {
+		i := 0
+		for i < len(rl.Results) && (rl.Results[i].White != r.White || rl.Results[i].Black != r.Black) {
+			i++
+		}
+		if i == len(rl.Results) {
+			rl.Results = append(rl.Results, representation.PairResult{White: r.White, Black: r.Black})
+		}
+		rl.Results[i].Wins += r.Wins
+		rl.Results[i].Draws += r.Draws
+		rl.Results[i].Losses += r.Losses
+	}
+	rl.Ratings = representation.BayesElo(rl.Results)
+}
+
+// Save writes the rating list to path, through a temporary file so that an interrupted write leaves the
+// previous list intact
+func (rl *RatingList) Save(path string) error {
+	return writeJSON(path, rl)
+}
+
+// Write prints the ratings, best first
+func (rl *RatingList) Write(w io.Writer) {
+	width := len("Engine")
+	for _, r := range rl.Ratings {
+		width = max(width, len(r.Name))
+	}
+	fmt.Fprintf(w, "%3s  %-*s %7s %7s %6s %6s\n", "#", width, "Engine", "Elo", "+/-", "Games", "Score")
+	for i, r := range rl.Ratings {
+		fmt.Fprintf(w, "%3d  %-*s %7.0f %7.0f %6d %5.1f%%\n", i+1, width, r.Name, r.Elo, r.Margin, r.Games, 100*r.Points/float64(max(r.Games, 1)))
+	}
+}
+
+// writeJSON writes v as indented JSON to path through a temporary file
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// --- Tournament command
+
+func runTournamentCommand(args []string, out io.Writer) error {
+	usage := fmt.Errorf("usage: morris tournament [--mode round-robin|gauntlet] [--games N] [--concurrency N] [--opening-plies N] [--max-plies N] [--seed N] [--ratings FILE] [--json FILE] [--out FILE] config_file\n" +
+		"  config_file: one engine SPEC per line, # starting a comment\n" + engineSpecUsage)
+	flags := newFlagSet("tournament")
+	mode := flags.String("mode", RoundRobin, "pairings, round-robin or gauntlet")
+	gamesPerPairing := flags.Int("games", 2, "games of every pairing, colors alternating")
+	concurrency := flags.Int("concurrency", runtime.NumCPU(), "games played at the same time")
+	openingPlies := flags.Int("opening-plies", 8, "random plies of the openings")
+	maxPlies := flags.Int("max-plies", 200, "plies after which a game is drawn")
+	seed := flags.Int64("seed", 1, "seed of the opening suite")
+	ratingsPath := flags.String("ratings", "ratings.json", "rating list file, updated with the results")
+	jsonPath := flags.String("json", "", "file to write the crosstable to as JSON")
+	output := flags.String("out", "", "record file of the games")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if len(positional) != 1 || *gamesPerPairing < 1 || *mode != RoundRobin && *mode != Gauntlet {
+		return usage
+	}
+	engines, err := readTournamentConfig(positional[0])
+	if err != nil {
+		return err
+	}
+	ratings, err := LoadRatingList(*ratingsPath)
+	if err != nil {
+		return err
+	}
+
+	// Every pairing plays the same openings, each with both colors
+	openings, err := balancedOpenings(*seed, *gamesPerPairing, *openingPlies)
+	if err != nil {
+		return err
+	}
+	var jobs []gameJob
+	for _, pair := range pairings(*mode, len(engines)) {
+		a, b := engines[pair[0]], engines[pair[1]]
+		for index := 0; index < *gamesPerPairing; index++ {
+			if index%2 == 0 {
+				jobs = append(jobs, gameJob{a, b, openings[index/2]})
+			} else {
+				jobs = append(jobs, gameJob{b, a, openings[index/2]})
+			}
+		}
+	}
+	finished := 0
+	games := playGames(jobs, *concurrency, *maxPlies, func(index int, g *representation.Game) bool {
+		finished++
+		fmt.Fprintf(out, "Game %d/%d (%s vs %s): %s\n", finished, len(jobs), g.Header("White"), g.Header("Black"), g.Result)
+		return false
+	})
+
+	var results []representation.PairResult
+	for _, g := range games {
+		results = append(results, pairResult(g))
+	}
+	ct := newCrosstable(*mode, *gamesPerPairing, engines, results)
+	fmt.Fprintln(out)
+	ct.Write(out)
+	if *jsonPath != "" {
+		if err := writeJSON(*jsonPath, ct); err != nil {
+			return fmt.Errorf("failed to write crosstable: %v", err)
+		}
+	}
+
+	ratings.Add(results)
+	if err := ratings.Save(*ratingsPath); err != nil {
+		return fmt.Errorf("failed to write rating list: %v", err)
+	}
+	fmt.Fprintf(out, "\nRating list %s:\n", *ratingsPath)
+	ratings.Write(out)
+
+	if *output == "" {
+		return nil
+	}
+	return writeGames(*output, games)
+}
+
+// pairResult returns the result of a game as a PairResult of one game
+func pairResult(g *representation.Game) representation.PairResult {
+	r := representation.PairResult{White: g.Header("White"), Black: g.Header("Black")}
+	switch g.Result {
+	case representation.WhiteWins:
+		r.Wins = 1
+	case representation.BlackWins:
+		r.Losses = 1
+	default:
+		r.Draws = 1
+	}
+	return r
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test the pairings of both modes
+func TestPairings(t *testing.T) {
+	if got := pairings(RoundRobin, 4); len(got) != 6 {
+		t.Errorf("round-robin: %v", got)
+	}
+	if got := pairings(Gauntlet, 4); len(got) != 3 || got[2] != [2]int{0, 3} {
+		t.Errorf("gauntlet: %v", got)
+	}
+}
+
+// Test that a tournament prints and writes its crosstable, and that the rating list adds up the games of
+// every tournament
+func TestTournamentCommand(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "engines.txt")
+	os.WriteFile(config, []byte("# three engines\nname=A,eval=material,depth=1\n\nname=B,depth=1\nname=C,algo=minimax,depth=1,time=1s\n"), 0644)
+	ratings, crosstable := filepath.Join(dir, "ratings.json"), filepath.Join(dir, "crosstable.json")
+
+	got, err := run(t, "morris", "tournament", config, "--games", "2", "--concurrency", "2", "--ratings", ratings, "--json", crosstable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(got, "Game ") != 6 || !strings.Contains(got, "---") || !strings.Contains(got, "Rating list ") {
+		t.Errorf("output:\n%s", got)
+	}
+	var ct Crosstable
+	data, _ := os.ReadFile(crosstable)
+	if err := json.Unmarshal(data, &ct); err != nil {
+		t.Fatal(err)
+	}
+	var points float64
+	for _, row := range ct.Engines {
+		points += row.Points
+		if row.Games != 4 || len(row.Opponents) != 3 {
+			t.Errorf("row %+v", row)
+		}
+	}
+	if ct.Mode != RoundRobin || points != 6 {
+		t.Errorf("crosstable %+v", ct)
+	}
+
+	if _, err := run(t, "morris", "tournament", config, "--mode", "gauntlet", "--games", "2", "--ratings", ratings); err != nil {
+		t.Fatal(err)
+	}
+	rl, err := LoadRatingList(ratings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	games := map[string]int{}
+	for _, r := range rl.Ratings {
+		games[r.Name] = r.Games
+	}
+	if len(rl.Results) != 6 || games["A"] != 8 || games["B"] != 6 || games["C"] != 6 {
+		t.Errorf("rating list %+v", rl)
+	}
+}
+
+// Test that a config needs two engines with different names
+func TestTournamentConfigErrors(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "engines.txt")
+	for content, want := range map[string]string{
+		"depth=1\n":             "at least 2",
+		"depth=1\ndepth=1\n":    "taken",
+		"depth=1\nspeed=fast\n": ":2: invalid engine spec",
+	} {
+		os.WriteFile(config, []byte(content), 0644)
+		if _, err := readTournamentConfig(config); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: %v, want %s", content, err, want)
+		}
+	}
+}
@@ -0,0 +1,513 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"representation"
+	"strings"
+)
+
+// --- Full-screen terminal UI
+//
+// The board is drawn with its lines, taken from Neighbors, on a character canvas four columns and two rows
+// per grid step. A cursor moves between the squares with the arrow keys; Enter places a piece, or selects a
+// piece and then its destination, and after a mill the piece to remove. The side panel shows the game and
+// what the engine found at every depth of its iterative deepening while it thinks.
+
+const (
+	canvasWidth  = 6*4 + 1
+	canvasHeight = 6*2 + 1
+)
+
+// ANSI styles of the canvas and the screen
+const (
+	styleReset       = "\x1b[0m"
+	styleCursor      = "7"                    // Reverse video
+	styleSelected    = "1;4"                  // Bold, underlined
+	styleDestination = "32;1"                 // Bold green
+	styleCapture     = "31;1"                 // Bold red
+	styleMill        = "33"                   // Yellow
+	screenEnter      = "\x1b[?1049h\x1b[?25l" // Alternate screen, cursor hidden
+	screenLeave      = "\x1b[?25h\x1b[?1049l"
+	screenClear      = "\x1b[H\x1b[2J"
+)
+
+// canvasPoint returns the column and row of a square on the canvas
+func canvasPoint(position int) (int, int) {
+	name := representation.SquareName(position)
+	return int(name[0]-'a') * 4, (6 - int(name[1]-'0')) * 2
+}
+
+// progress is what the engine found at one depth of its iterative deepening
+type progress struct {
+	Depth  int
+	Result representation.SearchResult
+	Done   bool // The last depth, whose move is played
+}
+
+// think searches p at every depth up to the engine's and sends the result of each
+func think(p representation.Position, engine representation.Engine, updates chan<- progress) {
+	for depth := 1; depth <= engine.Depth; depth++ {
+		updates <- progress{Depth: depth, Result: representation.Search(p, depth, engine.Evaluator), Done: depth == engine.Depth}
+	}
+}
+
+// tui is the state of the terminal UI, on top of the game state of a session
+type tui struct {
+	s        *session
+	cursor   int
+	selected int                   // Piece chosen to move, NoSquare if none
+	pending  []representation.Move // Moves to the chosen destination, which differ in the piece removed
+	thinking bool
+	info     progress // Last progress of the engine
+	lastMove representation.Move
+	message  string
+}
+
+func newTUI(s *session) *tui {
+	none := representation.Move{From: representation.NoSquare, To: representation.NoSquare, Remove: representation.NoSquare}
+	return &tui{s: s, selected: representation.NoSquare, lastMove: none, message: "Your move"}
+}
+
+// humanToMove reports whether the game waits for a move of the human
+func (t *tui) humanToMove() bool {
+	return !t.thinking && t.s.result() == representation.Unfinished && t.s.position().SideToMove == t.s.human
+}
+
+// engineToMove reports whether the engine should start thinking
+func (t *tui) engineToMove() bool {
+	return !t.thinking && t.s.result() == representation.Unfinished && t.s.position().SideToMove != t.s.human
+}
+
+func (t *tui) legalMoves() []representation.Move {
+	if !t.humanToMove() {
+		return nil
+	}
+	return t.s.position().LegalMoves()
+}
+
+// highlights returns the squares to mark as legal destinations and as capture candidates
+func (t *tui) highlights() (map[int]bool, map[int]bool) {
+	destinations, captures := map[int]bool{}, map[int]bool{}
+	for _, m := range t.pending {
+		captures[m.Remove] = true
+	}
+	if len(t.pending) > 0 {
+		return destinations, captures
+	}
+	for _, m := range t.legalMoves() {
+		if m.From == t.selected {
+			destinations[m.To] = true
+		}
+	}
+	return destinations, captures
+}
+
+// millSquares returns the squares of every closed mill, and the pairs of adjacent squares along them
+func millSquares(board *representation.MorrisBoard) (map[int]bool, map[[2]int]bool) {
+	squares, pairs := map[int]bool{}, map[[2]int]bool{}
+	for _, line := range representation.MillLines() {
+		color := board.GetPosition(line[0])
+		if color == representation.Empty || board.GetPosition(line[1]) != color || board.GetPosition(line[2]) != color {
+			continue
+		}
+		for i, a := range line {
+			squares[a] = true
+			for _, b := range line[i+1:] {
+				pairs[[2]int{min(a, b), max(a, b)}] = true
+			}
+		}
+	}
+	return squares, pairs
+}
+
+// canvas draws the board lines and squares with their highlights, one string per row
+func (t *tui) canvas() []string {
+	p := t.s.position()
+	board := &p.Board
+	cells := make([][]string, canvasHeight)
+	for y := range cells {
+		cells[y] = make([]string, canvasWidth)
+		for x := range cells[y] {
+			cells[y][x] = " "
+		}
+	}
+
+	mills, millPairs := millSquares(board)
+	for a := 0; a < 21; a++ {
+		for _, b := range representation.Neighbors(a) {
+			if b < a {
+				continue
+			}
+			x0, y0 := canvasPoint(a)
+			x1, y1 := canvasPoint(b)
+			dx, dy := x1-x0, y1-y0
+			char := "-"
+			switch {
+			case dx == 0:
+				char = "|"
+			case dy != 0 && (dx > 0) == (dy > 0):
+				char = "\\"
+			case dy != 0:
+				char = "/"
+			}
+			if millPairs[[2]int{a, b}] {
+				char = "\x1b[" + styleMill + "m" + char + styleReset
+			}
+			steps := max(abs(dx), abs(dy))
+			for i := 1; i < steps; i++ {
+				cells[y0+dy*i/steps][x0+dx*i/steps] = char
+			}
+		}
+	}
+
+	destinations, captures := t.highlights()
+	for position := 0; position < 21; position++ {
+		piece := map[int]string{representation.Empty: "o", representation.White: "W", representation.Black: "B"}[board.GetPosition(position)]
+		var styles []string
+		if position == t.cursor {
+			styles = append(styles, styleCursor)
+		}
+		switch {
+		case position == t.selected:
+			styles = append(styles, styleSelected)
+		case captures[position]:
+			styles = append(styles, styleCapture)
+		case destinations[position]:
+			styles = append(styles, styleDestination)
+		case mills[position]:
+			styles = append(styles, styleMill)
+		}
+		if len(styles) > 0 {
+			piece = "\x1b[" + strings.Join(styles, ";") + "m" + piece + styleReset
+		}
+		x, y := canvasPoint(position)
+		cells[y][x] = piece
+	}
+
+	rows := make([]string, canvasHeight)
+	for y, row := range cells {
+		rows[y] = strings.Join(row, "")
+	}
+	return rows
+}
+
+// panel returns the lines of the side panel
+func (t *tui) panel() []string {
+	p := t.s.position()
+	engineState := "waiting"
+	if t.thinking {
+		engineState = fmt.Sprintf("thinking, depth %d of %d done", t.info.Depth, t.s.engine.Depth)
+	}
+	lines := []string{
+		"Morris-B",
+		fmt.Sprintf("You play %s against %s", colorName(t.s.human), t.s.engine.Name),
+		fmt.Sprintf("Move %d, %s to move", p.MoveNumber, colorName(p.SideToMove)),
+		fmt.Sprintf("In hand: White %d, Black %d", p.WhiteInHand, p.BlackInHand),
+		fmt.Sprintf("On board: White %d, Black %d", p.Pieces(representation.White)-p.WhiteInHand, p.Pieces(representation.Black)-p.BlackInHand),
+		fmt.Sprintf("Last move: %s", t.lastMove),
+		"",
+		fmt.Sprintf("Engine: %s", engineState),
+		fmt.Sprintf("Score: %d", t.info.Result.Estimate),
+		fmt.Sprintf("Nodes: %d", t.info.Result.Nodes),
+		fmt.Sprintf("PV: %s", formatMoves(t.info.Result.PV)),
+		"",
+	}
+	if result := t.s.result(); result != representation.Unfinished {
+		lines = append(lines, "Game over: "+result)
+	} else {
+		lines = append(lines, t.message)
+	}
+	return append(lines, "",
+		"arrows/hjkl move, enter select, esc cancel",
+		"u undo, r redo, s switch sides, n new game",
+		"+/- engine depth, q quit")
+}
+
+// render returns the whole screen
+func (t *tui) render() string {
+	rows, panel := t.canvas(), t.panel()
+	var sb strings.Builder
+	sb.WriteString(screenClear)
+	for y := 0; y < max(len(rows), len(panel)+1); y++ {
+		line := strings.Repeat(" ", canvasWidth+2)
+		if y < len(rows) {
+			line = fmt.Sprintf("%d %s", 6-y/2, rows[y])
+			if y%2 == 1 {
+				line = "  " + rows[y]
+			}
+		}
+		if y > 0 && y <= len(panel) {
+			line += "    " + panel[y-1]
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("  a   b   c   d   e   f   g\n")
+	return sb.String()
+}
+
+// moveCursor moves the cursor to the nearest square in the direction, on the same row or column if there is
+// one, and stays at the edge
+func (t *tui) moveCursor(dx int, dy int) {
+	x0, y0 := canvasPoint(t.cursor)
+	best, bestDistance := t.cursor, 0
+	for position := 0; position < 21; position++ {
+		x, y := canvasPoint(position)
+		along, across := (x-x0)*dx+(y-y0)*dy, abs((x-x0)*dy)+abs((y-y0)*dx)
+		if along <= 0 {
+			continue
+		}
+		distance := along + 2*across
+		if across > 0 {
+			distance += 1000 // Off the row or column
+		}
+		if best == t.cursor || distance < bestDistance {
+			best, bestDistance = position, distance
+		}
+	}
+	t.cursor = best
+}
+
+// choose acts on Enter at the cursor: a placement, the piece to move or its destination, or the removal
+func (t *tui) choose() {
+	moves, square, p := t.legalMoves(), t.cursor, t.s.position()
+	if len(moves) == 0 {
+		return
+	}
+	if len(t.pending) > 0 {
+		for _, m := range t.pending {
+			if m.Remove == square {
+				t.playHuman(m)
+				return
+			}
+		}
+		t.message = "Choose a red piece to remove"
+		return
+	}
+
+	placing := moves[0].From == representation.NoSquare
+	if !placing && (t.selected == representation.NoSquare || p.Board.GetPosition(square) == t.s.human) {
+		t.selected = representation.NoSquare
+		for _, m := range moves {
+			if m.From == square {
+				t.selected = square
+			}
+		}
+		if t.selected == representation.NoSquare {
+			t.message = fmt.Sprintf("No move from %s", representation.SquareName(square))
+		} else {
+			t.message = "Choose a green destination"
+		}
+		return
+	}
+
+	var candidates []representation.Move
+	for _, m := range moves {
+		if m.From == t.selected && m.To == square {
+			candidates = append(candidates, m)
+		}
+	}
+	switch {
+	case len(candidates) == 0:
+		t.message = fmt.Sprintf("Cannot move to %s", representation.SquareName(square))
+	case len(candidates) == 1 && candidates[0].Remove == representation.NoSquare:
+		t.playHuman(candidates[0])
+	default:
+		t.pending = candidates
+		t.message = "Mill! Choose a red piece to remove"
+	}
+}
+
+func (t *tui) playHuman(m representation.Move) {
+	t.s.play(representation.RecordedMove{Move: m})
+	t.s.redo = nil
+	t.lastMove = m
+	t.cancel()
+	t.message = "Your move"
+}
+
+// cancel drops the piece selected and the capture pending
+func (t *tui) cancel() {
+	t.selected, t.pending = representation.NoSquare, nil
+}
+
+// onProgress takes a result of the engine, and plays its move after the last depth
+func (t *tui) onProgress(p progress) {
+	t.info = p
+	if p.Done {
+		t.s.play(representation.RecordedMove{Move: p.Result.Move, Eval: p.Result.Estimate, HasEval: true})
+		t.s.redo = nil
+		t.lastMove, t.thinking = p.Result.Move, false
+	}
+}
+
+// handleKey acts on a key and reports whether the UI should quit. While the engine thinks only q works.
+func (t *tui) handleKey(key string) bool {
+	if key == "q" || key == "ctrl-c" {
+		return true
+	}
+	if t.thinking {
+		return false
+	}
+	switch key {
+	case "up", "k":
+		t.moveCursor(0, -1)
+	case "down", "j":
+		t.moveCursor(0, 1)
+	case "left", "h":
+		t.moveCursor(-1, 0)
+	case "right", "l":
+		t.moveCursor(1, 0)
+	case "enter", " ":
+		t.choose()
+	case "esc":
+		t.cancel()
+	case "u":
+		if len(t.s.moves) == 0 {
+			t.message = "Nothing to undo"
+		}
+		t.cancel()
+		t.s.undo()
+	case "r":
+		if len(t.s.redo) == 0 {
+			t.message = "Nothing to redo"
+		}
+		t.cancel()
+		t.s.replay()
+	case "s":
+		t.cancel()
+		t.s.human = 3 - t.s.human
+	case "n":
+		t.cancel()
+		t.s.positions, t.s.moves, t.s.redo = t.s.positions[:1], nil, nil
+	case "+", "-":
+		if key == "+" {
+			t.s.engine.Depth++
+		} else {
+			t.s.engine.Depth = max(t.s.engine.Depth-1, 1)
+		}
+		t.s.engine.Name = fmt.Sprintf("morris depth %d", t.s.engine.Depth)
+	}
+	return false
+}
+
+// parseKeys splits terminal input into key names: arrows, enter, esc, ctrl-c or the character typed
+func parseKeys(input []byte) []string {
+	var keys []string
+	for len(input) > 0 {
+		if len(input) >= 3 && input[0] == 0x1b && (input[1] == '[' || input[1] == 'O') {
+			if key, found := map[byte]string{'A': "up", 'B': "down", 'C': "right", 'D': "left"}[input[2]]; found {
+				keys = append(keys, key)
+			}
+			input = input[3:]
+			continue
+		}
+		switch input[0] {
+		case 0x1b:
+			keys = append(keys, "esc")
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		case 0x03:
+			keys = append(keys, "ctrl-c")
+		default:
+			keys = append(keys, string(input[0]))
+		}
+		input = input[1:]
+	}
+	return keys
+}
+
+// runTUI runs the UI on the terminal of in and out until the human quits
+func runTUI(in *os.File, out io.Writer, t *tui) error {
+	restore, err := makeRaw(int(in.Fd()))
+	if err != nil {
+		return err
+	}
+	defer restore()
+	fmt.Fprint(out, screenEnter)
+	defer fmt.Fprint(out, screenLeave)
+
+	keys := make(chan string)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, err := in.Read(buf)
+			for _, key := range parseKeys(buf[:n]) {
+				keys <- key
+			}
+			if err != nil {
+				close(keys)
+				return
+			}
+		}
+	}()
+
+	updates := make(chan progress)
+	for {
+		if t.engineToMove() {
+			t.thinking, t.info = true, progress{}
+			go think(t.s.position(), t.s.engine, updates)
+		}
+		fmt.Fprint(out, t.render())
+		select {
+		case key, ok := <-keys:
+			if !ok || t.handleKey(key) {
+				return nil
+			}
+		case p := <-updates:
+			t.onProgress(p)
+		}
+	}
+}
+
+func runTUICommand(args []string, out io.Writer) error {
+	usage := fmt.Errorf("usage: morris tui [--human W|B] [--depth N] [--eval NAME | --weights FILE] [--side W|B] [--phase opening|midgame] [input_file]")
+	evaluator, args, err := representation.ParseEvaluatorFlag(args)
+	if err != nil {
+		return err
+	}
+	flags := newFlagSet("tui")
+	humanName := flags.String("human", "W", "side played from the terminal")
+	depth := flags.Int("depth", 3, "search depth")
+	sideName := flags.String("side", "W", "side to move of a legacy board")
+	phaseName := flags.String("phase", "opening", "phase of a legacy board")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if len(positional) > 1 || *depth < 1 {
+		return usage
+	}
+	human, err := parseSide(*humanName)
+	if err != nil {
+		return err
+	}
+	side, err := parseSide(*sideName)
+	if err != nil {
+		return err
+	}
+	phase, err := parsePhase(*phaseName)
+	if err != nil {
+		return err
+	}
+	if human == 0 || side == 0 || phase == -1 {
+		return usage
+	}
+
+	start := representation.StartPosition()
+	if len(positional) == 1 {
+		if start, _, err = representation.ReadLegacyPosition(io.Discard, positional[0], side, phase); err != nil {
+			return err
+		}
+	}
+	engine := representation.Engine{Name: fmt.Sprintf("morris depth %d", *depth), Evaluator: evaluator, Depth: *depth}
+	return runTUI(os.Stdin, out, newTUI(newSession(strings.NewReader(""), io.Discard, start, human, engine)))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
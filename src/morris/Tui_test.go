@@ -0,0 +1,108 @@
+package main
+
+import (
+	"representation"
+	"strings"
+	"testing"
+)
+
+func newTestTUI(t *testing.T, start string, human int) *tui {
+	t.Helper()
+	s, _ := newTestSession(t, "", start, human)
+	return newTUI(s)
+}
+
+// Test the key names of terminal input
+func TestParseKeys(t *testing.T) {
+	got := strings.Join(parseKeys([]byte("\x1b[A\x1b[Dq\r\x1b \x03")), ",")
+	if want := "up,left,q,enter,esc, ,ctrl-c"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// Test that the cursor goes to the nearest square in every direction and stays at the edge
+func TestMoveCursor(t *testing.T) {
+	ui := newTestTUI(t, representation.StartPosition().String(), representation.White)
+	steps := []struct {
+		key, want string
+	}{
+		{"right", "g0"}, {"right", "g0"}, {"up", "g3"}, {"up", "g6"}, {"left", "d6"}, {"down", "d5"}, {"h", "b5"}, {"j", "b3"},
+		{"l", "c3"}, {"k", "c4"},
+	}
+	for _, step := range steps {
+		ui.handleKey(step.key)
+		if got := representation.SquareName(ui.cursor); got != step.want {
+			t.Fatalf("%s: cursor on %s, want %s", step.key, got, step.want)
+		}
+	}
+}
+
+// Test selecting a piece, its destinations, the capture after a mill and the engine's reply
+func TestChooseMoveWithCapture(t *testing.T) {
+	ui := newTestTUI(t, "WBxBWxxWxxxxxxxxBxxWB W 0 0 0 20", representation.White)
+	ui.cursor, _ = representation.ParseSquare("b3")
+	ui.handleKey("enter")
+	if ui.selected != ui.cursor {
+		t.Fatalf("b3 not selected: %s", ui.message)
+	}
+	destinations, _ := ui.highlights()
+	b1, _ := representation.ParseSquare("b1")
+	if !destinations[b1] {
+		t.Errorf("destinations %v miss b1", destinations)
+	}
+	if !strings.Contains(ui.render(), "\x1b["+styleDestination+"m") {
+		t.Error("destinations not highlighted")
+	}
+
+	ui.cursor = b1
+	ui.handleKey("enter")
+	_, captures := ui.highlights()
+	if len(ui.pending) == 0 || len(captures) == 0 {
+		t.Fatalf("no capture pending after closing a0-b1-c2: %s", ui.message)
+	}
+	ui.handleKey("enter") // b1 is no capture candidate
+	if len(ui.s.moves) != 0 {
+		t.Fatal("a move was played without a removal")
+	}
+	for square := range captures {
+		ui.cursor = square
+		break
+	}
+	ui.handleKey("enter")
+	if len(ui.s.moves) != 1 || ui.s.moves[0].Move.Remove == representation.NoSquare || !ui.engineToMove() {
+		t.Fatalf("moves %+v, engine to move %v", ui.s.moves, ui.engineToMove())
+	}
+
+	// The engine's progress is shown while it thinks and its move played after the last depth
+	ui.thinking = true
+	updates := make(chan progress, 1)
+	go think(ui.s.position(), ui.s.engine, updates)
+	for p := range updates {
+		if ui.handleKey("u") || len(ui.s.moves) != 1 {
+			t.Fatal("keys work while the engine thinks")
+		}
+		ui.onProgress(p)
+		if !strings.Contains(ui.render(), "Nodes: ") {
+			t.Fatal("panel without nodes")
+		}
+		if p.Done {
+			break
+		}
+	}
+	if len(ui.s.moves) != 2 || ui.thinking || !ui.humanToMove() && ui.s.result() == representation.Unfinished {
+		t.Errorf("after the engine: moves %+v, thinking %v", ui.s.moves, ui.thinking)
+	}
+}
+
+// Test that closed mills are highlighted and that q quits
+func TestMillHighlightAndQuit(t *testing.T) {
+	ui := newTestTUI(t, "WBWBWxxxxxxxxxxxxxxxx W 6 7 0 3", representation.White)
+	p := ui.s.position()
+	squares, pairs := millSquares(&p.Board)
+	if len(squares) != 3 || len(pairs) != 3 {
+		t.Errorf("mill squares %v, pairs %v", squares, pairs)
+	}
+	if !ui.handleKey("q") {
+		t.Error("q does not quit")
+	}
+}
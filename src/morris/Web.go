@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"representation"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// --- Browser front-end
+//
+// The web command serves the analysis API together with a static front-end embedded in the binary, so that
+// it works offline. The front-end draws the board from /api/board, asks /api/moves for the legal moves and
+// follows the engine's search live through the Server-Sent Events of /api/stream.
+
+//go:embed web
+var webFiles embed.FS
+
+// boardResponse describes the board for drawing it, the squares in board order and the lines between them,
+// and the game's start and draw rule
+type boardResponse struct {
+	Squares       []string
+	Lines         [][2]string
+	Start         string // Start position
+	DrawHalfmoves int
+}
+
+// streamEvent is the data of an info event of /api/stream, one per search depth
+type streamEvent struct {
+	Depth    int
+	Estimate int // White's point of view
+	Score    string
+	Nodes    int
+	PV       []string
+	Time     int // Milliseconds
+}
+
+// webHandler adds the front-end, the board description and the search stream to the API
+func (s *server) webHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/api/", s.handler())
+	mux.HandleFunc("GET /api/board", s.board)
+	mux.HandleFunc("GET /api/stream", s.stream)
+	static, _ := fs.Sub(webFiles, "web")
+	mux.Handle("/", http.FileServer(http.FS(static)))
+	return mux
+}
+
+func (s *server) board(w http.ResponseWriter, r *http.Request) {
+	response := boardResponse{Squares: representation.SquareNames[:], Start: representation.StartPosition().String(), DrawHalfmoves: representation.DrawHalfmoves}
+	for position := 0; position < 21; position++ {
+		for _, neighbor := range representation.Neighbors(position) {
+			if neighbor > position {
+				response.Lines = append(response.Lines, [2]string{representation.SquareName(position), representation.SquareName(neighbor)})
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// stream searches the position of the query's position, eval, depth and movetime parameters, sending an info
+// event after every depth and a bestmove event with the move at the end, or an error event. Closing the event
+// source cancels the search.
+func (s *server) stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	send := func(event string, data any) {
+		encoded, _ := json.Marshal(data)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, encoded)
+		flusher.Flush()
+	}
+
+	query := r.URL.Query()
+	req := apiRequest{Position: query.Get("position"), Eval: query.Get("eval"), Algo: query.Get("algo")}
+	var err error
+	for name, field := range map[string]*int{"depth": &req.Depth, "movetime": &req.MoveTime} {
+		if value := query.Get(name); value != "" && err == nil {
+			if *field, err = strconv.Atoi(value); err != nil {
+				err = badRequest("invalid %s %s", name, value)
+			}
+		}
+	}
+	if err == nil {
+		var response any
+		response, err = s.searchStreaming(ctx, req, func(r representation.SearchResult, p representation.Position, elapsed time.Duration) {
+			send("info", streamEvent{Depth: r.Depth, Estimate: r.Estimate, Score: protocolScore(r.Estimate, p.SideToMove), Nodes: r.Nodes,
+				PV: moveStrings(r.PV), Time: int(elapsed.Milliseconds())})
+		})
+		if err == nil {
+			send("bestmove", response)
+			return
+		}
+	}
+	if r.Context().Err() == nil {
+		send("error", apiError{err.Error()})
+	}
+}
+
+func runWebCommand(args []string, out io.Writer) error {
+	usage := fmt.Errorf("usage: morris web [--addr HOST:PORT] [--workers N] [--timeout DURATION] [--max-depth N] [--eval NAME | --weights FILE]")
+	evaluator, args, err := representation.ParseEvaluatorFlag(args)
+	if err != nil {
+		return err
+	}
+	flags := newFlagSet("web")
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	workers := flags.Int("workers", runtime.NumCPU(), "searches run at the same time")
+	timeout := flags.Duration("timeout", time.Minute, "time after which a search is cancelled")
+	maxDepth := flags.Int("max-depth", 8, "deepest search the page may ask for")
+	positional, err := parseFlags(flags, args)
+	if err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if len(positional) != 0 || *workers < 1 || *timeout <= 0 || *maxDepth < 1 {
+		return usage
+	}
+
+	s := newServer(evaluator, *workers, *timeout, *maxDepth)
+	fmt.Fprintf(out, "Open http://%s/ to play the engine\n", *addr)
+	return http.ListenAndServe(*addr, s.webHandler())
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"representation"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test that the embedded front-end is served next to the API, with the board it draws
+func TestWebFrontEnd(t *testing.T) {
+	e, _ := representation.LookupEvaluator(representation.DefaultEvaluator)
+	ts := httptest.NewServer(newServer(e, 1, 10*time.Second, 8).webHandler())
+	defer ts.Close()
+
+	for path, want := range map[string]string{"/": "<svg id=\"board\"", "/app.js": "new EventSource", "/style.css": "#evalbar"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != 200 || !strings.Contains(string(body), want) {
+			t.Errorf("%s: %s, misses %q", path, resp.Status, want)
+		}
+	}
+
+	var board boardResponse
+	resp, err := http.Get(ts.URL + "/api/board")
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.NewDecoder(resp.Body).Decode(&board)
+	resp.Body.Close()
+	if len(board.Squares) != 21 || len(board.Lines) == 0 || board.Lines[0] != [2]string{"a0", "g0"} || board.Start != representation.StartPosition().String() {
+		t.Errorf("board %+v", board)
+	}
+
+	var m movesResponse
+	if status := post(t, context.Background(), ts.URL+"/api/moves", `{}`, &m); status != 200 || len(m.Moves) != 21 {
+		t.Errorf("API behind the front-end: %d %+v", status, m)
+	}
+}
+
+// Test that the stream sends an info event per depth, then the best move, or an error
+func TestWebStream(t *testing.T) {
+	e, _ := representation.LookupEvaluator(representation.DefaultEvaluator)
+	ts := httptest.NewServer(newServer(e, 1, 10*time.Second, 8).webHandler())
+	defer ts.Close()
+
+	stream := func(query url.Values) string {
+		resp, err := http.Get(ts.URL + "/api/stream?" + query.Encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("content type %s", ct)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	got := stream(url.Values{"position": {"xxxxxxxxxxxxxxxxxxxxx W 9 9 0 1 a0"}, "depth": {"3"}})
+	if strings.Count(got, "event: info\n") != 3 || !strings.Contains(got, `"Depth":3`) || !strings.Contains(got, "event: bestmove\ndata: {\"Position\":\"Wxxxxxxxxxxxxxxxxxxxx B 8 9 0 1\",\"Move\":\"") {
+		t.Errorf("stream:\n%s", got)
+	}
+	if got = stream(url.Values{"depth": {"deep"}}); !strings.HasPrefix(got, "event: error\n") {
+		t.Errorf("stream:\n%s", got)
+	}
+}
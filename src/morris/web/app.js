@@ -0,0 +1,265 @@
+'use strict';
+
+// A game against the engine: the board comes from /api/board, the legal moves and the game state from
+// /api/moves and /api/validate, and the engine's moves from the search stream of /api/stream.
+
+const svgNS = 'http://www.w3.org/2000/svg';
+const moveSyntax = /^(?:([a-g][0-6])-)?([a-g][0-6])(?:x([a-g][0-6]))?$/;
+
+const state = {
+  squares: [],   // Square names in board order
+  lines: [],     // Pairs of square names joined on the board
+  start: '',     // Start position
+  drawHalfmoves: 50, // Halfmove clock at which the game is drawn
+  moves: [],     // Moves played, in square notation
+  pieces: {},    // Square name to White or Black
+  legal: [],     // Legal moves as {text, from, to, remove}
+  sideToMove: 'White',
+  outcome: '*',
+  selected: {},  // Squares clicked so far: from, then to while a removal is pending
+  source: null,  // Event source of the running search
+  message: '',
+};
+
+const $ = (id) => document.getElementById(id);
+
+function human() {
+  return $('side').value;
+}
+
+// point returns the SVG coordinates of a square, column a to g left to right and row 6 at the top
+function point(square) {
+  return [50 + 100 * (square.charCodeAt(0) - 97), 50 + 100 * (6 - Number(square[1]))];
+}
+
+async function api(endpoint, body) {
+  const response = await fetch('/api/' + endpoint, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(body),
+  });
+  const data = await response.json();
+  if (!response.ok) {
+    throw new Error(data.Error);
+  }
+  return data;
+}
+
+function positionText() {
+  return [state.start, ...state.moves].join(' ');
+}
+
+function parseMove(text) {
+  const [, from, to, remove] = text.match(moveSyntax);
+  return { text, from, to, remove };
+}
+
+// refresh reads the position after the moves, then lets the engine move if it is its turn
+async function refresh() {
+  stopSearch();
+  state.selected = {};
+  try {
+    const position = await api('validate', { Position: positionText() });
+    const moves = await api('moves', { Position: positionText() });
+    const board = position.Position.split(' ')[0];
+    state.pieces = {};
+    state.squares.forEach((square, i) => {
+      if (board[i] === 'W') state.pieces[square] = 'White';
+      if (board[i] === 'B') state.pieces[square] = 'Black';
+    });
+    state.sideToMove = position.SideToMove;
+    state.outcome = position.Outcome;
+    if (state.outcome === '*' && Number(position.Position.split(' ')[4]) >= state.drawHalfmoves) {
+      state.outcome = '1/2-1/2';
+    }
+    state.legal = moves.Moves.map(parseMove);
+    state.message = '';
+  } catch (err) {
+    state.message = err.message;
+  }
+  render();
+  if (state.outcome === '*' && state.sideToMove !== human()) {
+    engineMove();
+  }
+}
+
+// engineMove follows the engine's search, showing every depth, and plays its best move
+function engineMove() {
+  const query = new URLSearchParams({ position: positionText(), depth: $('depth').value });
+  const source = new EventSource('/api/stream?' + query);
+  state.source = source;
+  state.message = 'The engine is thinking…';
+  render();
+  source.addEventListener('info', (event) => showSearch(JSON.parse(event.data)));
+  source.addEventListener('bestmove', (event) => {
+    stopSearch();
+    const result = JSON.parse(event.data);
+    showSearch(result);
+    if (result.Move) {
+      state.moves.push(result.Move);
+    }
+    refresh();
+  });
+  source.addEventListener('error', (event) => {
+    stopSearch();
+    state.message = event.data ? JSON.parse(event.data).Error : 'Lost the connection to the engine';
+    render();
+  });
+}
+
+function stopSearch() {
+  if (state.source) {
+    state.source.close();
+    state.source = null;
+  }
+}
+
+// showSearch updates the evaluation bar, the search line and the PV
+function showSearch(info) {
+  // Logarithmic, so that a piece of the simple evaluators and a won game both show
+  const e = info.Estimate;
+  const share = 0.5 + 0.5 * Math.sign(e) * Math.min(1, Math.log10(1 + Math.abs(e)) / 5);
+  $('evalwhite').style.height = (100 * share).toFixed(1) + '%';
+  $('search').textContent = `Depth ${info.Depth}, score ${info.Score}, ${info.Nodes} nodes, ${info.Time} ms`;
+  $('pv').textContent = info.PV.join(' ');
+}
+
+function click(square) {
+  if (state.source || state.outcome !== '*' || state.sideToMove !== human()) {
+    return;
+  }
+  const sel = state.selected;
+  const moving = state.legal.some((m) => m.from);
+  if (sel.to) {
+    // A mill was closed, the click picks the piece to remove
+    const move = state.legal.find((m) => m.from === sel.from && m.to === sel.to && m.remove === square);
+    if (move) play(move);
+    return;
+  }
+  if (moving && state.legal.some((m) => m.from === square)) {
+    state.selected = { from: square };
+    render();
+    return;
+  }
+  const candidates = state.legal.filter((m) => m.to === square && m.from === sel.from);
+  if (candidates.length === 0) {
+    return;
+  }
+  if (candidates[0].remove) {
+    state.selected = { from: sel.from, to: square };
+    state.message = 'Mill! Click the piece to remove.';
+    render();
+  } else {
+    play(candidates[0]);
+  }
+}
+
+function play(move) {
+  state.moves.push(move.text);
+  refresh();
+}
+
+// highlights returns the class of every square that can be clicked next
+function highlights() {
+  const classes = {};
+  if (state.source || state.outcome !== '*' || state.sideToMove !== human()) {
+    return classes;
+  }
+  const sel = state.selected;
+  for (const m of state.legal) {
+    if (sel.to) {
+      if (m.from === sel.from && m.to === sel.to) classes[m.remove] = 'capture';
+    } else if (m.from && !sel.from) {
+      classes[m.from] = 'selectable';
+    } else if (m.from === sel.from) {
+      classes[m.to] = 'destination';
+    }
+  }
+  if (sel.from && !sel.to) {
+    classes[sel.from] = 'selectable';
+  }
+  return classes;
+}
+
+function render() {
+  const svg = $('board');
+  svg.replaceChildren();
+  const add = (name, attributes) => {
+    const element = document.createElementNS(svgNS, name);
+    for (const [key, value] of Object.entries(attributes)) element.setAttribute(key, value);
+    svg.appendChild(element);
+    return element;
+  };
+
+  for (const [a, b] of state.lines) {
+    const [x1, y1] = point(a);
+    const [x2, y2] = point(b);
+    add('line', { x1, y1, x2, y2 });
+  }
+  for (let i = 0; i < 7; i++) {
+    add('text', { x: 42 + 100 * i, y: 695 }).textContent = String.fromCharCode(97 + i);
+    add('text', { x: 2, y: 58 + 100 * (6 - i) }).textContent = String(i);
+  }
+
+  const classes = highlights();
+  const last = state.moves.length ? parseMove(state.moves[state.moves.length - 1]) : {};
+  for (const square of state.squares) {
+    const [cx, cy] = point(square);
+    const target = add('circle', { cx, cy, r: 14, class: 'point ' + (classes[square] || '') });
+    target.addEventListener('click', () => click(square));
+    const title = document.createElementNS(svgNS, 'title');
+    title.textContent = square;
+    target.appendChild(title);
+    if (state.pieces[square]) {
+      let pieceClass = 'piece ' + state.pieces[square];
+      if (classes[square] === 'capture') pieceClass += ' capture';
+      else if (classes[square] === 'selectable') pieceClass += ' movable';
+      else if (square === last.to) pieceClass += ' last';
+      add('circle', { cx, cy, r: 30, class: pieceClass }).addEventListener('click', () => click(square));
+    }
+  }
+
+  let status;
+  if (state.outcome === '1-0') status = 'White wins';
+  else if (state.outcome === '0-1') status = 'Black wins';
+  else if (state.outcome === '1/2-1/2') status = 'Draw';
+  else status = state.sideToMove === human() ? 'Your move' : 'Engine to move';
+  $('status').textContent = state.message ? `${status}. ${state.message}` : status;
+
+  const history = $('history');
+  history.replaceChildren();
+  for (let i = 0; i < state.moves.length; i += 2) {
+    const li = document.createElement('li');
+    li.textContent = state.moves.slice(i, i + 2).join('  ');
+    history.appendChild(li);
+  }
+  history.scrollTop = history.scrollHeight;
+}
+
+async function init() {
+  const response = await fetch('/api/board');
+  const board = await response.json();
+  state.squares = board.Squares;
+  state.lines = board.Lines;
+  state.start = board.Start;
+  state.drawHalfmoves = board.DrawHalfmoves;
+
+  $('new').addEventListener('click', () => {
+    state.moves = [];
+    $('search').textContent = '';
+    $('pv').textContent = '';
+    $('evalwhite').style.height = '50%';
+    refresh();
+  });
+  $('undo').addEventListener('click', () => {
+    // Back to the human's previous turn
+    stopSearch();
+    state.moves.pop();
+    if (state.moves.length % 2 !== (human() === 'White' ? 0 : 1)) state.moves.pop();
+    refresh();
+  });
+  $('side').addEventListener('change', refresh);
+  refresh();
+}
+
+init();
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"representation"
+	"strconv"
+	"strings"
+)
+
+/*
+Requirements:
+
+ 1. get command line args
+    X file 1 (board or extended position, optionally followed by the moves played from it, the input file name)
+    X depth of tree to be counted
+    X side to move (W or B), for a legacy board only
+    X phase of the game (opening or midgame), for a legacy board only
+    X optional "divide" to break the count down per root move
+    X optional "distinct" to count distinct positions at the given depth, before and after merging symmetric ones
+
+2. output:
+  - input board position (list of 21 characters)
+  - with divide, every root move, the board position after it and the leaf count below it
+  - total number of leaf positions at the given depth
+  - with distinct, the number of distinct positions at the given depth, and how many remain after symmetry reduction
+
+An extended position carries its own side to move and pieces in hand, and its tree crosses from the
+opening into the midgame when the pieces in hand run out. A legacy board is counted in a single phase.
+*/
+
+func PerftMain() error {
+	// Check number of command-line arguments, early return if invalid
+	if len(os.Args) < 3 {
+		return fmt.Errorf("usage: Perft <input_file> <depth> [W|B] [opening|midgame] [divide] [distinct]")
+	}
+
+	// Extract command-line arguments
+	file1, depthStr := os.Args[1], os.Args[2]
+
+	// Convert depth string to integer
+	depth, err := strconv.Atoi(depthStr)
+	if err != nil || depth < 0 {
+		return fmt.Errorf("invalid depth: %s", depthStr)
+	}
+
+	color, phase, divide, distinct := 0, -1, false, false
+	for _, arg := range os.Args[3:] {
+		switch arg {
+		case "W":
+			color = representation.White
+		case "B":
+			color = representation.Black
+		case "opening":
+			phase = representation.Opening
+		case "midgame":
+			phase = representation.MidgameEndgame
+		case "divide":
+			divide = true
+		case "distinct":
+			distinct = true
+		default:
+			return fmt.Errorf("invalid argument: %s", arg)
+		}
+	}
+
+	// Read input board file
+	inputBoard, err := os.ReadFile(file1)
+	if err != nil {
+		return fmt.Errorf("failed to read input board file: %v", err)
+	}
+
+	// Print input board position
+	fmt.Printf("Input position: %s\n", strings.TrimSpace(string(inputBoard)))
+
+	// An extended position is counted with the pieces in hand, a legacy board with the given side and phase
+	var entries []representation.PerftEntry
+	var nodes, positions, canonical int
+	if representation.IsExtendedPosition(string(inputBoard)) {
+		position, moves, err := representation.ParsePositionAndMoves(string(inputBoard), representation.White, representation.Opening)
+		if err != nil {
+			return fmt.Errorf("invalid input position %s: %v", file1, err)
+		}
+		if len(moves) > 0 {
+			fmt.Printf("Position after moves: %s\n", position.String())
+		}
+		if distinct {
+			positions, canonical = representation.CountDistinctPosition(position, depth)
+		}
+		if divide && depth > 0 {
+			entries = representation.PerftPositionDivide(position, depth)
+		} else {
+			nodes = representation.PerftPosition(position, depth)
+		}
+	} else {
+		if color == 0 || phase == -1 {
+			return fmt.Errorf("a legacy board needs the side to move (W or B) and the phase (opening or midgame)")
+		}
+		// Convert inputBoard from string to board using the method, ParseBoardAndMoves
+		board, moves, err := representation.ParseBoardAndMoves(string(inputBoard), color, phase)
+		if err != nil {
+			return fmt.Errorf("invalid input board %s: %v", file1, err)
+		}
+		if len(moves) > 0 {
+			fmt.Printf("Position after moves: %s\n", board.String())
+		}
+		if distinct {
+			positions, canonical = representation.CountDistinct(board, color, depth, phase)
+		}
+		if divide && depth > 0 {
+			entries = representation.PerftDivide(board, color, depth, phase)
+		} else {
+			nodes = representation.Perft(board, color, depth, phase)
+		}
+	}
+
+	// Print the count per root move if asked to
+	for _, entry := range entries {
+		fmt.Printf("%s %s: %d\n", entry.Move.String(), entry.Board.String(), entry.Nodes)
+		nodes += entry.Nodes
+	}
+
+	fmt.Printf("Nodes searched: %d\n", nodes)
+
+	// Print the distinct positions, with and without symmetric ones merged
+	if distinct {
+		fmt.Printf("Distinct positions: %d\n", positions)
+		fmt.Printf("Distinct positions after symmetry reduction (%d symmetries): %d\n", len(representation.Symmetries()), canonical)
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package representation
+
+import (
+	"math"
+	"sort"
+)
+
+// --- Elo estimation
+
+// ScoreElo returns the Elo difference at which the stronger side is expected to score the given fraction of
+// the points, infinite for a score of 0 or 1
+func ScoreElo(score float64) float64 {
+	return -400 * math.Log10(1/score-1)
+}
+
+// EloScore returns the fraction of the points expected from a side that many Elo stronger, the inverse of
+// ScoreElo
+func EloScore(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// varianceGames is the weight, in games, of the variance of a game between equal engines without draws (1/4)
+// that the observed variance is blended with. A match won or lost game after game has no variance of its own,
+// and would otherwise get no error margin and an LLR of 0.
+const varianceGames = 1
+
+// scoreVariance returns the mean score of the first engine and the variance of the points of one game,
+// regularized by varianceGames
+func (r MatchResult) scoreVariance() (float64, float64) {
+	games := float64(r.Wins + r.Draws + r.Losses)
+	if games == 0 {
+		return 0.5, 0.25
+	}
+	s := r.Score()
+	variance := (float64(r.Wins)*(1-s)*(1-s) + float64(r.Draws)*(0.5-s)*(0.5-s) + float64(r.Losses)*s*s) / games
+	return s, (games*variance + varianceGames*0.25) / (games + varianceGames)
+}
+
+// Elo returns the Elo difference of the first engine over the second and the half width of its 95%
+// confidence interval, both derived from the score and its standard error. Scores are kept as far from 0 and
+// 1, where the Elo difference is infinite, as one more game drawn would leave them, so that a match won or
+// lost game after game gives a finite bound.
+func (r MatchResult) Elo() (float64, float64) {
+	s, variance := r.scoreVariance()
+	games := float64(r.Wins + r.Draws + r.Losses)
+	if games == 0 {
+		return 0, math.Inf(1)
+	}
+	clamp := func(score float64) float64 {
+		return math.Min(math.Max(score, 0.5/(games+1)), 1-0.5/(games+1))
+	}
+	stderr := math.Sqrt(variance / games)
+	s, low, high := clamp(s), clamp(s-1.96*stderr), clamp(s+1.96*stderr)
+	return ScoreElo(s), (ScoreElo(high) - ScoreElo(low)) / 2
+}
+
+// --- Sequential probability ratio test
+
+// SPRT verdicts
+const (
+	SPRTContinue = "continue" // Play more games
+	SPRTAcceptH1 = "H1 accepted"
+	SPRTAcceptH0 = "H0 accepted"
+)
+
+// SPRT tests whether the first engine of a match is Elo1 rather than Elo0 stronger than the second, with
+// false positive rate Alpha and false negative rate Beta
+type SPRT struct {
+	Elo0, Elo1  float64
+	Alpha, Beta float64
+}
+
+// Bounds returns the log likelihood ratios below which H0 and above which H1 is accepted
+func (t SPRT) Bounds() (float64, float64) {
+	return math.Log(t.Beta / (1 - t.Alpha)), math.Log((1 - t.Beta) / t.Alpha)
+}
+
+// LLR returns the log likelihood ratio of H1 over H0 given the result, the score being approximated by a
+// normal distribution of the regularized observed variance
+func (t SPRT) LLR(r MatchResult) float64 {
+	s, variance := r.scoreVariance()
+	s0, s1 := EloScore(t.Elo0), EloScore(t.Elo1)
+	games := float64(r.Wins + r.Draws + r.Losses)
+	return games * (s1 - s0) * (2*s - s0 - s1) / (2 * variance)
+}
+
+// Verdict returns SPRTAcceptH1 or SPRTAcceptH0 once the log likelihood ratio leaves its bounds, SPRTContinue
+// before
+func (t SPRT) Verdict(r MatchResult) string {
+	llr := t.LLR(r)
+	lower, upper := t.Bounds()
+	switch {
+	case llr >= upper:
+		return SPRTAcceptH1
+	case llr <= lower:
+		return SPRTAcceptH0
+	}
+	return SPRTContinue
+}
+
+// --- Bayesian Elo
+
+// PairResult counts the games one engine played with White against another
+type PairResult struct {
+	White, Black        string
+	Wins, Draws, Losses int // From White's point of view
+}
+
+// Parameters of the BayesElo model, the defaults of Rémi Coulom's bayeselo
+const (
+	BayesEloAdvantage = 32.8 // Elo the first move is worth
+	BayesEloDrawElo   = 97.3 // Elo difference below which a draw is likely
+	BayesEloPrior     = 2    // Virtual draws between every two engines that met, which keep ratings finite
+)
+
+// Rating is the BayesElo rating of an engine
+type Rating struct {
+	Name   string
+	Elo    float64 // Relative to the average of the rated engines
+	Margin float64 // Half width of the 95% confidence interval, 0 if the results do not bound the rating
+	Games  int
+	Points float64
+}
+
+// BayesElo returns the maximum a posteriori ratings of the engines of the results, best first. The
+// probability that White wins is EloScore(white - black + BayesEloAdvantage - BayesEloDrawElo), that Black
+// wins EloScore(black - white - BayesEloAdvantage - BayesEloDrawElo), and a draw takes the rest.
+func BayesElo(results []PairResult) []Rating {
+	type pair struct {
+		white, black        int
+		wins, draws, losses float64
+	}
+	index := map[string]int{}
+	var ratings []Rating
+	player := func(name string) int {
+		if i, found := index[name]; found {
+			return i
+		}
+		index[name] = len(ratings)
+		ratings = append(ratings, Rating{Name: name})
+		return len(ratings) - 1
+	}
+	var pairs []pair
+	met := map[[2]int]bool{}
+	for _, r := range results {
+		w, b := player(r.White), player(r.Black)
+		pairs = append(pairs, pair{w, b, float64(r.Wins), float64(r.Draws), float64(r.Losses)})
+		ratings[w].Games += r.Wins + r.Draws + r.Losses
+		ratings[b].Games += r.Wins + r.Draws + r.Losses
+		ratings[w].Points += float64(r.Wins) + float64(r.Draws)/2
+		ratings[b].Points += float64(r.Losses) + float64(r.Draws)/2
+		if key := [2]int{min(w, b), max(w, b)}; !met[key] && w != b {
+			met[key] = true
+			pairs = append(pairs, pair{w, b, 0, BayesEloPrior / 2, 0}, pair{b, w, 0, BayesEloPrior / 2, 0})
+		}
+	}
+
+	elo := make([]float64, len(ratings))
+	logLikelihood := func(i int) float64 {
+		var sum float64
+		for _, p := range pairs {
+			if p.white != i && p.black != i {
+				continue
+			}
+			delta := elo[p.white] - elo[p.black] + BayesEloAdvantage
+			win, loss := EloScore(delta-BayesEloDrawElo), EloScore(-delta-BayesEloDrawElo)
+			sum += p.wins*math.Log(win) + p.losses*math.Log(loss) + p.draws*math.Log(1-win-loss)
+		}
+		return sum
+	}
+	// derivatives returns the first and second derivatives of the log likelihood in the rating of i
+	derivatives := func(i int) (float64, float64) {
+		const h = 1.0
+		l := logLikelihood(i)
+		elo[i] += h
+		above := logLikelihood(i)
+		elo[i] -= 2 * h
+		below := logLikelihood(i)
+		elo[i] += h
+		return (above - below) / (2 * h), (above - 2*l + below) / (h * h)
+	}
+
+	// Newton steps on one rating at a time; the log likelihood is concave, so they converge
+	for iteration := 0; iteration < 1000; iteration++ {
+		change := 0.0
+		for i := range elo {
+			first, second := derivatives(i)
+			if second >= 0 {
+				continue
+			}
+			step := math.Max(-100, math.Min(100, -first/second))
+			elo[i] += step
+			change = math.Max(change, math.Abs(step))
+		}
+		if change < 0.001 {
+			break
+		}
+	}
+
+	var mean float64
+	for _, e := range elo {
+		mean += e / float64(len(elo))
+	}
+	for i := range ratings {
+		ratings[i].Elo = elo[i] - mean
+		if _, second := derivatives(i); second < 0 {
+			ratings[i].Margin = 1.96 / math.Sqrt(-second)
+		}
+	}
+	sort.SliceStable(ratings, func(i, j int) bool { return ratings[i].Elo > ratings[j].Elo })
+	return ratings
+}
@@ -0,0 +1,127 @@
+package representation
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Test the Elo difference of known scores and that EloScore inverts it
+func TestScoreElo(t *testing.T) {
+	cases := []struct {
+		score, elo float64
+	}{
+		{0.5, 0}, {0.75, 190.85}, {0.25, -190.85}, {10.0 / 11, 400},
+	}
+	for _, c := range cases {
+		if got := ScoreElo(c.score); math.Abs(got-c.elo) > 0.01 {
+			t.Errorf("ScoreElo(%v) = %v, want %v", c.score, got, c.elo)
+		}
+		if got := EloScore(c.elo); math.Abs(got-c.score) > 1e-4 {
+			t.Errorf("EloScore(%v) = %v, want %v", c.elo, got, c.score)
+		}
+	}
+}
+
+// Test the Elo and error margin of a match, the margin shrinking as the games grow
+func TestMatchElo(t *testing.T) {
+	elo, margin := MatchResult{Wins: 30, Draws: 40, Losses: 30}.Elo()
+	if elo != 0 || margin < 40 || margin > 60 {
+		t.Errorf("even match: %v +/- %v", elo, margin)
+	}
+	elo, bigger := MatchResult{Wins: 60, Draws: 20, Losses: 20}.Elo()
+	if math.Abs(elo-147.2) > 0.1 {
+		t.Errorf("elo %v, want 147.2", elo)
+	}
+	_, smaller := MatchResult{Wins: 600, Draws: 200, Losses: 200}.Elo()
+	if smaller >= bigger/3 {
+		t.Errorf("margin %v for 1000 games, %v for 100", smaller, bigger)
+	}
+	for _, r := range []MatchResult{{Wins: 10}, {Losses: 10}, {Wins: 1}} {
+		if elo, margin := r.Elo(); math.IsInf(elo, 0) || math.IsNaN(margin) || math.IsInf(margin, 0) || margin <= 0 {
+			t.Errorf("%+v: %v +/- %v", r, elo, margin)
+		}
+	}
+	if elo, _ := (MatchResult{Wins: 10}).Elo(); math.Abs(elo-ScoreElo(10.5/11)) > 1e-9 {
+		t.Errorf("10 wins: elo %v, want that of 10 wins and a draw", elo)
+	}
+}
+
+// Test that the SPRT accepts H1 for a clearly stronger engine, H0 for an equal one, and waits on few games
+func TestSPRTVerdict(t *testing.T) {
+	sprt := SPRT{Elo0: 0, Elo1: 50, Alpha: 0.05, Beta: 0.05}
+	lower, upper := sprt.Bounds()
+	if math.Abs(lower+2.944) > 0.001 || math.Abs(upper-2.944) > 0.001 {
+		t.Errorf("bounds %v, %v", lower, upper)
+	}
+	cases := []struct {
+		r    MatchResult
+		want string
+	}{
+		{MatchResult{Wins: 3, Draws: 2, Losses: 1}, SPRTContinue},
+		{MatchResult{Wins: 150, Draws: 50, Losses: 50}, SPRTAcceptH1},
+		{MatchResult{Wins: 200, Draws: 200, Losses: 200}, SPRTAcceptH0},
+		{MatchResult{Draws: 10}, SPRTContinue},
+		{MatchResult{Wins: 2}, SPRTContinue},
+		{MatchResult{Wins: 10}, SPRTAcceptH1},
+		{MatchResult{Losses: 10}, SPRTAcceptH0},
+	}
+	for _, c := range cases {
+		if got := sprt.Verdict(c.r); got != c.want {
+			t.Errorf("%+v: %s (LLR %v), want %s", c.r, got, sprt.LLR(c.r), c.want)
+		}
+	}
+}
+
+// Test that balanced openings are distinct, even in material and free of forced captures
+func TestBalancedOpeningSuite(t *testing.T) {
+	suite := BalancedOpeningSuite(rand.New(rand.NewSource(1)), 20, 8)
+	if len(suite) != 20 {
+		t.Fatalf("%d openings", len(suite))
+	}
+	seen := map[Position]bool{}
+	for _, p := range suite {
+		if seen[p] || p.Pieces(White) != p.Pieces(Black) {
+			t.Errorf("opening %s repeated or unbalanced", p)
+		}
+		seen[p] = true
+		if Search(p, 2, materialEvaluator{}).Estimate != StaticEstimateOpeningNaive(&p.Board) {
+			t.Errorf("opening %s has a forced capture", p)
+		}
+	}
+}
+
+// Test that BayesElo ranks by results, centers the ratings, counts the first move's advantage and keeps
+// perfect scores finite
+func TestBayesElo(t *testing.T) {
+	ratings := BayesElo([]PairResult{
+		{White: "A", Black: "B", Wins: 8, Draws: 2, Losses: 0},
+		{White: "B", Black: "A", Wins: 1, Draws: 2, Losses: 7},
+		{White: "B", Black: "C", Wins: 6, Draws: 4, Losses: 0},
+		{White: "C", Black: "B", Wins: 2, Draws: 3, Losses: 5},
+	})
+	if len(ratings) != 3 || ratings[0].Name != "A" || ratings[1].Name != "B" || ratings[2].Name != "C" {
+		t.Fatalf("ratings %+v", ratings)
+	}
+	var sum float64
+	for _, r := range ratings {
+		sum += r.Elo
+		if r.Margin <= 0 || math.IsInf(r.Margin, 0) {
+			t.Errorf("%s: margin %v", r.Name, r.Margin)
+		}
+	}
+	if math.Abs(sum) > 1e-6 || ratings[1].Games != 40 || ratings[1].Points != 17.5 {
+		t.Errorf("ratings %+v", ratings)
+	}
+
+	// Even results with both colors are even ratings; White winning every game is worth less than that
+	even := BayesElo([]PairResult{{White: "A", Black: "B", Wins: 5, Losses: 5}, {White: "B", Black: "A", Wins: 5, Losses: 5}})
+	white := BayesElo([]PairResult{{White: "A", Black: "B", Wins: 10}, {White: "B", Black: "A", Wins: 10}})
+	if math.Abs(even[0].Elo) > 0.01 || math.Abs(white[0].Elo) > 0.01 {
+		t.Errorf("even %+v, white wins %+v", even, white)
+	}
+	perfect := BayesElo([]PairResult{{White: "A", Black: "B", Wins: 10}, {White: "B", Black: "A", Losses: 10}})
+	if perfect[0].Name != "A" || math.IsInf(perfect[0].Elo, 0) || perfect[0].Elo < 100 {
+		t.Errorf("perfect score %+v", perfect)
+	}
+}
@@ -0,0 +1,177 @@
+package representation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// --- Evaluators
+//
+// An evaluator is the static estimation function a search calls at its leaves. Evaluators are registered
+// under a name so that the CLIs can select one with --eval NAME without the search code knowing about it.
+
+// Evaluator estimates a board state from White's point of view: positive values favor White, negative
+// values favor Black. Phase is Opening or MidgameEndgame, sideToMove the color to move in the board state.
+type Evaluator interface {
+	Evaluate(board *MorrisBoard, phase int, sideToMove int) int
+}
+
+// PositionEvaluator is implemented by evaluators that use the pieces in hand, which a bare board does not
+// hold. Search calls EvaluatePosition instead of Evaluate on them.
+type PositionEvaluator interface {
+	EvaluatePosition(p Position) int
+}
+
+// EvaluatorFunc adapts an ordinary function to the Evaluator interface
+type EvaluatorFunc func(board *MorrisBoard, phase int, sideToMove int) int
+
+// Evaluate calls f(board, phase, sideToMove)
+func (f EvaluatorFunc) Evaluate(board *MorrisBoard, phase int, sideToMove int) int {
+	return f(board, phase, sideToMove)
+}
+
+// DefaultEvaluator is the name of the evaluator used when none is selected, the static estimation
+// functions of Morris-B.pdf
+const DefaultEvaluator = "standard"
+
+var (
+	evaluatorsMu sync.RWMutex
+	evaluators   = map[string]Evaluator{}
+)
+
+func init() {
+	RegisterEvaluator(DefaultEvaluator, standardEvaluator{})
+	RegisterEvaluator("material", materialEvaluator{})
+}
+
+// standardEvaluator calls the static estimation function of the phase
+type standardEvaluator struct{}
+
+func (standardEvaluator) Evaluate(board *MorrisBoard, phase int, sideToMove int) int {
+	if phase == Opening {
+		return StaticEstimateOpeningNaive(board)
+	}
+	return StaticEstimateMidgameEndgame(board)
+}
+
+func (standardEvaluator) Explain(board *MorrisBoard, phase int, sideToMove int) Explanation {
+	if phase == Opening {
+		return explainOpeningNaive(board)
+	}
+	return explainMidgameEndgame(board)
+}
+
+// materialEvaluator counts White minus Black pieces in every phase
+type materialEvaluator struct{}
+
+func (materialEvaluator) Evaluate(board *MorrisBoard, phase int, sideToMove int) int {
+	return StaticEstimateOpeningNaive(board)
+}
+
+func (materialEvaluator) Explain(board *MorrisBoard, phase int, sideToMove int) Explanation {
+	return explainOpeningNaive(board)
+}
+
+// RegisterEvaluator makes an evaluator available under the given name. It is meant to be called from init
+// functions and panics if the name is empty or already taken.
+func RegisterEvaluator(name string, e Evaluator) {
+	evaluatorsMu.Lock()
+	defer evaluatorsMu.Unlock()
+	if name == "" || e == nil {
+		panic("representation: RegisterEvaluator needs a name and an evaluator")
+	}
+	if _, taken := evaluators[name]; taken {
+		panic("representation: evaluator " + name + " registered twice")
+	}
+	evaluators[name] = e
+}
+
+// UnknownEvaluatorError reports a name no evaluator is registered under
+type UnknownEvaluatorError struct {
+	Name string
+}
+
+func (e *UnknownEvaluatorError) Error() string {
+	return fmt.Sprintf("unknown evaluator %q, want one of %s", e.Name, strings.Join(EvaluatorNames(), ", "))
+}
+
+// LookupEvaluator returns the evaluator registered under name, or an UnknownEvaluatorError
+func LookupEvaluator(name string) (Evaluator, error) {
+	evaluatorsMu.RLock()
+	defer evaluatorsMu.RUnlock()
+	e, found := evaluators[name]
+	if !found {
+		return nil, &UnknownEvaluatorError{Name: name}
+	}
+	return e, nil
+}
+
+// EvaluatorNames returns the names of the registered evaluators in alphabetical order
+func EvaluatorNames() []string {
+	evaluatorsMu.RLock()
+	defer evaluatorsMu.RUnlock()
+	names := make([]string, 0, len(evaluators))
+	for name := range evaluators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseEvaluatorFlag takes the --eval NAME (or --eval=NAME, -eval NAME) and --weights FILE options out of
+// command line arguments, wherever they appear, and returns the selected evaluator together with the
+// remaining arguments. A weights file selects the evaluator it names, with its weights, and a network file
+// written by the train tool selects a Network. Without either option the DefaultEvaluator is returned.
+func ParseEvaluatorFlag(args []string) (Evaluator, []string, error) {
+	name, weights := DefaultEvaluator, ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		option, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "-"), "=")
+		if option != "-eval" && option != "eval" && option != "-weights" && option != "weights" || !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, nil, fmt.Errorf("%s needs a value", arg)
+			}
+			value = args[i+1]
+			i++
+		}
+		if strings.HasSuffix(option, "eval") {
+			name = value
+		} else {
+			weights = value
+		}
+	}
+
+	if weights != "" && IsNetworkFile(weights) {
+		if name != DefaultEvaluator {
+			return nil, nil, fmt.Errorf("--eval %s conflicts with network file %s", name, weights)
+		}
+		n, err := LoadNetwork(weights)
+		if err != nil {
+			return nil, nil, err
+		}
+		return n, rest, nil
+	}
+	if weights != "" {
+		t, weightsName, err := LoadWeights(weights)
+		if err != nil {
+			return nil, nil, err
+		}
+		if name != DefaultEvaluator && name != weightsName {
+			return nil, nil, fmt.Errorf("--eval %s conflicts with weights file %s for evaluator %s", name, weights, weightsName)
+		}
+		return t, rest, nil
+	}
+
+	e, err := LookupEvaluator(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, rest, nil
+}
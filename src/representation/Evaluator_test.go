@@ -0,0 +1,92 @@
+package representation
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// Test that the standard evaluator reproduces the static estimation functions of each phase
+func TestStandardEvaluator(t *testing.T) {
+	e, err := LookupEvaluator(DefaultEvaluator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"xxxxxxWxxBxxxxxxBxWxx", "xBxBWxxxxBxBWWxWWWBBB", "WxWxxxxxxxxxxxxxBxWBx"} {
+		board := MorrisBoardFromString(s)
+		if got, want := e.Evaluate(board, Opening, White), StaticEstimateOpeningNaive(board); got != want {
+			t.Errorf("%s in the opening: got %d, want %d", s, got, want)
+		}
+		if got, want := e.Evaluate(board, MidgameEndgame, Black), StaticEstimateMidgameEndgame(board); got != want {
+			t.Errorf("%s in the midgame: got %d, want %d", s, got, want)
+		}
+	}
+}
+
+// Test registering, listing and looking up evaluators by name
+func TestEvaluatorRegistry(t *testing.T) {
+	RegisterEvaluator("test-constant", EvaluatorFunc(func(board *MorrisBoard, phase int, sideToMove int) int { return 42 }))
+	defer func() {
+		evaluatorsMu.Lock()
+		delete(evaluators, "test-constant")
+		evaluatorsMu.Unlock()
+	}()
+
+	e, err := LookupEvaluator("test-constant")
+	if err != nil || e.Evaluate(&MorrisBoard{}, Opening, White) != 42 {
+		t.Fatalf("LookupEvaluator(test-constant) = %v, %v", e, err)
+	}
+	if names := EvaluatorNames(); !sort.StringsAreSorted(names) || !slices.Contains(names, "test-constant") || !slices.Contains(names, DefaultEvaluator) {
+		t.Errorf("EvaluatorNames() = %v, want the sorted names including %s and test-constant", names, DefaultEvaluator)
+	}
+
+	var unknown *UnknownEvaluatorError
+	if _, err := LookupEvaluator("nope"); !errors.As(err, &unknown) || unknown.Name != "nope" {
+		t.Errorf("LookupEvaluator(nope) error = %v, want UnknownEvaluatorError", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("registering test-constant twice did not panic")
+		}
+	}()
+	RegisterEvaluator("test-constant", EvaluatorFunc(func(board *MorrisBoard, phase int, sideToMove int) int { return 0 }))
+}
+
+// Test that --eval is taken out of the arguments in every spelling and position
+func TestParseEvaluatorFlag(t *testing.T) {
+	material, _ := LookupEvaluator("material")
+	tests := []struct {
+		args []string
+		rest []string
+		eval string
+	}{
+		{[]string{"in.txt", "out.txt", "3"}, []string{"in.txt", "out.txt", "3"}, DefaultEvaluator},
+		{[]string{"--eval", "material", "in.txt", "out.txt", "3"}, []string{"in.txt", "out.txt", "3"}, "material"},
+		{[]string{"in.txt", "out.txt", "3", "-eval=material"}, []string{"in.txt", "out.txt", "3"}, "material"},
+		{[]string{"in.txt", "--eval=material", "out.txt", "3"}, []string{"in.txt", "out.txt", "3"}, "material"},
+	}
+	for _, test := range tests {
+		e, rest, err := ParseEvaluatorFlag(test.args)
+		if err != nil {
+			t.Errorf("ParseEvaluatorFlag(%v): %v", test.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(rest, test.rest) {
+			t.Errorf("ParseEvaluatorFlag(%v) args = %v, want %v", test.args, rest, test.rest)
+		}
+		board := MorrisBoardFromString("xBxBWxxxxBxBWWxWWWBBB")
+		isMaterial := e.Evaluate(board, MidgameEndgame, White) == material.Evaluate(board, MidgameEndgame, White)
+		if isMaterial != (test.eval == "material") {
+			t.Errorf("ParseEvaluatorFlag(%v) selected the wrong evaluator, want %s", test.args, test.eval)
+		}
+	}
+
+	for _, args := range [][]string{{"in.txt", "--eval"}, {"--eval", "nope", "in.txt"}} {
+		if _, _, err := ParseEvaluatorFlag(args); err == nil {
+			t.Errorf("ParseEvaluatorFlag(%v) accepted invalid arguments", args)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package representation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- Evaluation breakdown
+
+// Term is one term of a static evaluation: a quantity of the board, the weight it is multiplied by and the
+// contribution that results, White's point of view
+type Term struct {
+	Name         string
+	Value        int
+	Weight       int
+	Contribution int
+}
+
+// Explanation breaks a static evaluation into its terms. Total is the sum of the contributions unless an
+// override, such as a side being left with 2 pieces, replaced it with a fixed value.
+type Explanation struct {
+	Terms    []Term
+	Override string // Why the total is not the sum of the terms, empty if it is
+	Total    int
+}
+
+// Explainer is implemented by evaluators that can break their estimate into terms. The Total of the
+// explanation equals what Evaluate returns.
+type Explainer interface {
+	Explain(board *MorrisBoard, phase int, sideToMove int) Explanation
+}
+
+// ExplainEvaluation explains the estimate of e for the board, as a single term if e is not an Explainer
+func ExplainEvaluation(e Evaluator, board *MorrisBoard, phase int, sideToMove int) Explanation {
+	if explainer, ok := e.(Explainer); ok {
+		return explainer.Explain(board, phase, sideToMove)
+	}
+	total := e.Evaluate(board, phase, sideToMove)
+	return Explanation{Terms: []Term{{Name: "evaluation", Value: total, Weight: 1, Contribution: total}}, Total: total}
+}
+
+// add appends a term with its contribution and adds it to the total
+func (x *Explanation) add(name string, value int, weight int) {
+	x.Terms = append(x.Terms, Term{Name: name, Value: value, Weight: weight, Contribution: value * weight})
+	x.Total += value * weight
+}
+
+// explainOpeningNaive breaks StaticEstimateOpeningNaive into its single term
+func explainOpeningNaive(board *MorrisBoard) Explanation {
+	var x Explanation
+	x.add("piece difference", countPieces(board, White)-countPieces(board, Black), 1)
+	return x
+}
+
+// explainMidgameEndgame breaks StaticEstimateMidgameEndgame into its terms and overrides
+func explainMidgameEndgame(board *MorrisBoard) Explanation {
+	var x Explanation
+	whitePieces, blackPieces := countPieces(board, White), countPieces(board, Black)
+	blackMoves := len(GenerateMovesMidgameEndgame(board, Black))
+	x.add("piece difference", whitePieces-blackPieces, 1000)
+	x.add("Black moves", blackMoves, -1)
+	x.add("White mills", countMills(board, White), 0) // Not weighted, shown for the mills that can reopen
+	x.add("Black mills", countMills(board, Black), 0)
+
+	switch {
+	case blackPieces <= 2:
+		x.Override, x.Total = "Black has 2 or fewer pieces", 10000
+	case whitePieces <= 2:
+		x.Override, x.Total = "White has 2 or fewer pieces", -10000
+	case blackMoves == 0:
+		x.Override, x.Total = "Black has no moves", 10000
+	}
+	return x
+}
+
+func countPieces(board *MorrisBoard, color int) int {
+	pieces := 0
+	for position := 0; position < 21; position++ {
+		if board.GetPosition(position) == color {
+			pieces++
+		}
+	}
+	return pieces
+}
+
+func countMills(board *MorrisBoard, color int) int {
+	closed := 0
+	for _, line := range millLines {
+		if board.GetPosition(line[0]) == color && board.GetPosition(line[1]) == color && board.GetPosition(line[2]) == color {
+			closed++
+		}
+	}
+	return closed
+}
+
+// FormatExplanations lays out explanations side by side, one row per term and one column pair (value and
+// contribution) per explanation, followed by their overrides and totals. The last column is the change of
+// every contribution from the first explanation to the last.
+func FormatExplanations(titles []string, xs []Explanation) string {
+	var names []string
+	weights := map[string]int{}
+	for _, x := range xs {
+		for _, term := range x.Terms {
+			if _, found := weights[term.Name]; !found {
+				names = append(names, term.Name)
+			}
+			weights[term.Name] = term.Weight
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-24s %8s", "term", "weight")
+	for _, title := range titles {
+		fmt.Fprintf(&sb, " %18s", title)
+	}
+	if len(xs) > 1 {
+		fmt.Fprintf(&sb, " %8s", "change")
+	}
+	sb.WriteString("\n")
+
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%-24s %8d", name, weights[name])
+		var contributions []int
+		for _, x := range xs {
+			value, contribution := 0, 0
+			for _, term := range x.Terms {
+				if term.Name == name {
+					value, contribution = term.Value, term.Contribution
+				}
+			}
+			contributions = append(contributions, contribution)
+			fmt.Fprintf(&sb, " %6d => %8d", value, contribution)
+		}
+		if len(xs) > 1 {
+			fmt.Fprintf(&sb, " %+8d", contributions[len(contributions)-1]-contributions[0])
+		}
+		sb.WriteString("\n")
+	}
+
+	for i, x := range xs {
+		if x.Override != "" {
+			fmt.Fprintf(&sb, "%s: override, %s\n", titles[i], x.Override)
+		}
+	}
+	fmt.Fprintf(&sb, "%-24s %8s", "total", "")
+	for _, x := range xs {
+		fmt.Fprintf(&sb, " %18d", x.Total)
+	}
+	if len(xs) > 1 {
+		fmt.Fprintf(&sb, " %+8d", xs[len(xs)-1].Total-xs[0].Total)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// PrincipalVariation follows the best replies of a searcher from the board, one ply less deep each time, and
+// returns the board states along the way, ending with the leaf whose estimate the search backed up. The
+// search function is one of the legacy searchers, such as MiniMaxAB with its evaluator bound.
+func PrincipalVariation(board *MorrisBoard, player int, depth int, phase int,
+	search func(board *MorrisBoard, player int, depth int) (*MorrisBoard, int, int)) []*MorrisBoard {
+	var line []*MorrisBoard
+	for ; depth > 0 && len(GenerateMoves(board, player, phase)) > 0; depth-- {
+		board, _, _ = search(board, player, depth)
+		line = append(line, board)
+		player = 3 - player
+	}
+	return line
+}
+
+// ExplainLine explains the evaluation at the root and at the leaf of a principal variation, as returned by
+// PrincipalVariation, and lists the moves of the line
+func ExplainLine(e Evaluator, root *MorrisBoard, player int, phase int, line []*MorrisBoard) string {
+	moves := make([]string, 0, len(line))
+	leaf, color := root, player
+	for _, b := range line {
+		moves = append(moves, MoveBetween(leaf, b, color).String())
+		leaf, color = b, 3-color
+	}
+
+	rootExplanation := ExplainEvaluation(e, root, phase, player)
+	leafExplanation := ExplainEvaluation(e, leaf, phase, color)
+	return fmt.Sprintf("Principal variation: %s\n", strings.Join(moves, " ")) +
+		FormatExplanations([]string{"root", "PV leaf"}, []Explanation{rootExplanation, leafExplanation})
+}
+
+// ParseExplainFlag takes the --explain (or -explain) option out of command line arguments, wherever it
+// appears, and reports whether it was given
+func ParseExplainFlag(args []string) (bool, []string) {
+	explain := false
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--explain" || arg == "-explain" {
+			explain = true
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+	return explain, rest
+}
@@ -0,0 +1,97 @@
+package representation
+
+import (
+	"math/rand"
+	"representation/reference"
+	"strings"
+	"testing"
+)
+
+// Test that every registered evaluator's explanation adds up to its estimate, in both phases
+func TestExplanationTotalsMatchEvaluate(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		s, _ := reference.RandomPosition(rng, rng.Intn(19), rng.Intn(20))
+		board := MorrisBoardFromString(s)
+		for _, name := range EvaluatorNames() {
+			e, _ := LookupEvaluator(name)
+			for _, phase := range []int{Opening, MidgameEndgame} {
+				x := ExplainEvaluation(e, board, phase, White)
+				if want := e.Evaluate(board, phase, White); x.Total != want {
+					t.Fatalf("%s on %s, phase %d: explanation total %d, Evaluate %d", name, s, phase, x.Total, want)
+				}
+				sum := 0
+				for _, term := range x.Terms {
+					if term.Contribution != term.Value*term.Weight {
+						t.Fatalf("%s on %s: term %+v does not multiply out", name, s, term)
+					}
+					sum += term.Contribution
+				}
+				if x.Override == "" && sum != x.Total {
+					t.Fatalf("%s on %s, phase %d: terms add up to %d, total %d without an override", name, s, phase, sum, x.Total)
+				}
+			}
+		}
+	}
+}
+
+// Test the terms and overrides of the midgame estimate
+func TestExplainMidgameEndgame(t *testing.T) {
+	b := mustParseBoard(t, "WWWxxxBxxBxxxxxxxxxxB")
+	x := explainMidgameEndgame(&b)
+	want := []Term{
+		{"piece difference", 0, 1000, 0},
+		{"Black moves", len(GenerateMovesMidgameEndgame(&b, Black)), -1, -len(GenerateMovesMidgameEndgame(&b, Black))},
+		{"White mills", 0, 0, 0}, // W W W on 0, 1, 2 is not a mill
+		{"Black mills", 0, 0, 0},
+	}
+	for i, term := range want {
+		if x.Terms[i] != term {
+			t.Errorf("term %d = %+v, want %+v", i, x.Terms[i], term)
+		}
+	}
+
+	b = mustParseBoard(t, "WxWxWxxxxxxxxxxxxxBBx")
+	if x := explainMidgameEndgame(&b); x.Override != "Black has 2 or fewer pieces" || x.Total != 10000 || x.Terms[2].Value != 1 {
+		t.Errorf("explanation of %s = %+v, want the 2 pieces override and one White mill", b.String(), x)
+	}
+}
+
+// Test that the principal variation of a searcher ends in the leaf its estimate comes from, and that the
+// breakdown shows both ends of it
+func TestExplainLine(t *testing.T) {
+	e, _ := LookupEvaluator("opening")
+	search := func(board *MorrisBoard, player int, depth int) (*MorrisBoard, int, int) {
+		p := LegacyPosition(*board, player, Opening)
+		r := Search(p, depth, e)
+		return p.Board.Apply(r.Move, player), r.Nodes, r.Estimate
+	}
+
+	board := MorrisBoardFromString("xxxxxxWxxBxxxxxxBxWxx")
+	_, _, estimate := search(board, White, 3)
+	line := PrincipalVariation(board, White, 3, Opening, search)
+	if len(line) != 3 {
+		t.Fatalf("principal variation has %d boards, want 3", len(line))
+	}
+	if leaf := e.Evaluate(line[2], Opening, White); leaf != estimate {
+		t.Errorf("PV leaf evaluates to %d, search estimate %d", leaf, estimate)
+	}
+
+	text := ExplainLine(e, board, White, Opening, line)
+	for _, want := range []string{"Principal variation: ", "root", "PV leaf", "two in a rows", "total"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("explanation lacks %q:\n%s", want, text)
+		}
+	}
+}
+
+// Test that --explain is taken out of the arguments wherever it is
+func TestParseExplainFlag(t *testing.T) {
+	explain, rest := ParseExplainFlag([]string{"in.txt", "--explain", "out.txt", "3"})
+	if !explain || strings.Join(rest, " ") != "in.txt out.txt 3" {
+		t.Errorf("ParseExplainFlag = %v, %v", explain, rest)
+	}
+	if explain, _ := ParseExplainFlag([]string{"in.txt", "out.txt", "3"}); explain {
+		t.Errorf("ParseExplainFlag found --explain where there is none")
+	}
+}
@@ -0,0 +1,362 @@
+package representation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// --- Game records
+//
+// A record file holds any number of games. Each game is a block of headers followed by its moves:
+//
+//	[White "MiniMaxAB depth 4"]
+//	[Black "Human"]
+//	[Date "2026.10.18"]
+//	[Variant "Morris-B"]
+//	[Result "1-0"]
+//
+//	1. d4 a0 2. c3 {[%eval 2] threatens c2} g0 ... 1-0
+//
+// A game that does not start from the empty board names its first position in extended notation in a
+// "Position" header. Comments are written in braces after the move they belong to, and an evaluation
+// inside a comment is written as [%eval N].
+
+// Results a game record can end with
+const (
+	WhiteWins  = "1-0"
+	BlackWins  = "0-1"
+	Draw       = "1/2-1/2"
+	Unfinished = "*"
+)
+
+// Header is one named value of a game record, such as its players or date
+type Header struct {
+	Name  string
+	Value string
+}
+
+// RecordedMove is a move of a game record with its annotations
+type RecordedMove struct {
+	Move    Move
+	Comment string // Free text comment, empty if none
+	Eval    int    // Evaluation of the move, White's point of view, valid if HasEval
+	HasEval bool
+	Line    int // Line of the record file the move was read from, 0 if it was not read from one
+}
+
+// Game is a recorded game: its headers, the position it starts from, its moves and its result
+type Game struct {
+	Headers []Header
+	Start   Position
+	Moves   []RecordedMove
+	Result  string
+}
+
+// NewGame returns a game starting from the empty board with the standard headers set
+func NewGame(white string, black string, date string) *Game {
+	g := &Game{Start: StartPosition(), Result: Unfinished}
+	g.SetHeader("White", white)
+	g.SetHeader("Black", black)
+	g.SetHeader("Date", date)
+	g.SetHeader("Variant", "Morris-B")
+	g.SetHeader("Result", Unfinished)
+	return g
+}
+
+// Header returns the value of the named header, or "" if the game has none
+func (g *Game) Header(name string) string {
+	for _, h := range g.Headers {
+		if h.Name == name {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// SetHeader sets the value of the named header, adding it after the others if it is new
+func (g *Game) SetHeader(name string, value string) {
+	for i, h := range g.Headers {
+		if h.Name == name {
+			g.Headers[i].Value = value
+			return
+		}
+	}
+	g.Headers = append(g.Headers, Header{Name: name, Value: value})
+}
+
+// SetResult sets the result of the game and its Result header
+func (g *Game) SetResult(result string) {
+	g.Result = result
+	g.SetHeader("Result", result)
+}
+
+// IllegalMoveError reports the first move of a game record that cannot be played
+type IllegalMoveError struct {
+	Ply      int      // Index of the move in the game, from 0
+	Line     int      // Line of the record file, 0 if unknown
+	Move     Move     // Offending move
+	Position Position // Position the move was played in
+}
+
+func (e *IllegalMoveError) Error() string {
+	return fmt.Sprintf("line %d: illegal move %s at ply %d in position %s", e.Line, e.Move.String(), e.Ply+1, e.Position.String())
+}
+
+// ValidateGame replays the game through the move generators and returns its final position, or an
+// IllegalMoveError for the first move that is not legal
+func ValidateGame(g *Game) (Position, error) {
+	p := g.Start
+	for ply, rm := range g.Moves {
+		next, err := p.Play(rm.Move)
+		if err != nil {
+			return p, &IllegalMoveError{Ply: ply, Line: rm.Line, Move: rm.Move, Position: p}
+		}
+		p = next
+	}
+	return p, nil
+}
+
+// --- Writing
+
+// GameWriter writes games one after the other to a record file
+type GameWriter struct {
+	w     *bufio.Writer
+	games int
+}
+
+// NewGameWriter returns a GameWriter writing to w
+func NewGameWriter(w io.Writer) *GameWriter {
+	return &GameWriter{w: bufio.NewWriter(w)}
+}
+
+// Write writes one game and flushes it to the underlying writer. A comment cannot hold '}', which ends it in
+// the record, so a game with one is not written.
+func (gw *GameWriter) Write(g *Game) error {
+	for i, rm := range g.Moves {
+		if strings.Contains(rm.Comment, "}") {
+			return fmt.Errorf("comment of move %d %s contains '}': %s", i+1, rm.Move.String(), rm.Comment)
+		}
+	}
+	if gw.games > 0 {
+		gw.w.WriteString("\n")
+	}
+	gw.games++
+
+	result := g.Result
+	if result == "" {
+		result = Unfinished
+	}
+	for _, h := range g.Headers {
+		fmt.Fprintf(gw.w, "[%s %s]\n", h.Name, strconv.Quote(h.Value))
+	}
+	if g.Header("Position") == "" && g.Start != StartPosition() {
+		fmt.Fprintf(gw.w, "[Position %s]\n", strconv.Quote(g.Start.String()))
+	}
+	gw.w.WriteString("\n")
+
+	// Move text, wrapped before 80 columns
+	var tokens []string
+	number, color := g.Start.MoveNumber, g.Start.SideToMove
+	for i, rm := range g.Moves {
+		if color == White {
+			tokens = append(tokens, fmt.Sprintf("%d.", number))
+		} else if i == 0 {
+			tokens = append(tokens, fmt.Sprintf("%d...", number))
+		}
+		tokens = append(tokens, rm.Move.String())
+		if rm.HasEval || rm.Comment != "" {
+			comment := rm.Comment
+			if rm.HasEval {
+				comment = strings.TrimSpace(fmt.Sprintf("[%%eval %d] %s", rm.Eval, rm.Comment))
+			}
+			tokens = append(tokens, "{"+comment+"}")
+		}
+		if color == Black {
+			number++
+		}
+		color = 3 - color
+	}
+	tokens = append(tokens, result)
+
+	width := 0
+	for _, token := range tokens {
+		if width > 0 && width+1+len(token) > 79 {
+			gw.w.WriteString("\n")
+			width = 0
+		} else if width > 0 {
+			gw.w.WriteString(" ")
+			width++
+		}
+		gw.w.WriteString(token)
+		width += len(token)
+	}
+	gw.w.WriteString("\n")
+
+	return gw.w.Flush()
+}
+
+// --- Reading
+
+// RecordSyntaxError reports text of a record file that cannot be read as part of a game
+type RecordSyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *RecordSyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// GameReader reads games one at a time from a record file
+type GameReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// NewGameReader returns a GameReader reading from r
+func NewGameReader(r io.Reader) *GameReader {
+	return &GameReader{s: bufio.NewScanner(r)}
+}
+
+func (gr *GameReader) next() (string, bool) {
+	if !gr.s.Scan() {
+		return "", false
+	}
+	gr.line++
+	return strings.TrimSpace(gr.s.Text()), true
+}
+
+// Read returns the next game of the record file, or io.EOF once there are no more.
+// Moves are only checked for notation; use ValidateGame to check that they are legal.
+func (gr *GameReader) Read() (*Game, error) {
+	g := &Game{Start: StartPosition()}
+
+	// Headers, after any blank lines left from the previous game
+	line, ok := gr.next()
+	for ok && line == "" {
+		line, ok = gr.next()
+	}
+	if !ok {
+		if err := gr.s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	for ; ok && strings.HasPrefix(line, "["); line, ok = gr.next() {
+		name, value, found := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(line, "["), "]"), " ")
+		unquoted, err := strconv.Unquote(strings.TrimSpace(value))
+		if !found || !strings.HasSuffix(line, "]") || err != nil {
+			return nil, &RecordSyntaxError{Line: gr.line, Msg: fmt.Sprintf("invalid header %s", line)}
+		}
+		g.SetHeader(name, unquoted)
+	}
+	if start := g.Header("Position"); start != "" {
+		p, err := ParsePosition(start)
+		if err != nil {
+			return nil, &RecordSyntaxError{Line: gr.line, Msg: fmt.Sprintf("invalid Position header: %v", err)}
+		}
+		g.Start = p
+	}
+
+	// Move text, up to and including the result
+	var comment strings.Builder
+	inComment := false
+	for ; ok; line, ok = gr.next() {
+		for rest := line; rest != ""; rest = strings.TrimSpace(rest) {
+			if inComment {
+				text, after, closed := strings.Cut(rest, "}")
+				comment.WriteString(text)
+				if !closed {
+					comment.WriteString(" ")
+					break
+				}
+				annotate(g, comment.String())
+				comment.Reset()
+				inComment, rest = false, after
+				continue
+			}
+			if strings.HasPrefix(rest, "{") {
+				if len(g.Moves) == 0 {
+					return nil, &RecordSyntaxError{Line: gr.line, Msg: "comment before the first move"}
+				}
+				inComment, rest = true, rest[1:]
+				continue
+			}
+
+			token, after, _ := strings.Cut(rest, " ")
+			if i := strings.Index(token, "{"); i > 0 {
+				token, after = token[:i], rest[i:]
+			}
+			rest = after
+			switch {
+			case token == WhiteWins || token == BlackWins || token == Draw || token == Unfinished:
+				g.Result = token
+				if rest != "" {
+					return nil, &RecordSyntaxError{Line: gr.line, Msg: fmt.Sprintf("text after the result: %s", rest)}
+				}
+				if g.Header("Result") == "" {
+					g.SetHeader("Result", token)
+				}
+				return g, nil
+			case strings.HasSuffix(token, "."):
+				if _, err := strconv.Atoi(strings.TrimRight(token, ".")); err != nil {
+					return nil, &RecordSyntaxError{Line: gr.line, Msg: fmt.Sprintf("invalid move number %s", token)}
+				}
+			default:
+				m, err := ParseMove(token)
+				if err != nil {
+					return nil, &RecordSyntaxError{Line: gr.line, Msg: err.Error()}
+				}
+				g.Moves = append(g.Moves, RecordedMove{Move: m, Line: gr.line})
+			}
+		}
+	}
+	if err := gr.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, &RecordSyntaxError{Line: gr.line, Msg: "game ends without a result"}
+}
+
+// annotate attaches a comment, and the evaluation it holds if any, to the last move of g
+func annotate(g *Game, comment string) {
+	rm := &g.Moves[len(g.Moves)-1]
+	comment = strings.TrimSpace(comment)
+	if rest, found := strings.CutPrefix(comment, "[%eval "); found {
+		value, text, closed := strings.Cut(rest, "]")
+		if eval, err := strconv.Atoi(strings.TrimSpace(value)); closed && err == nil {
+			rm.Eval, rm.HasEval = eval, true
+			comment = strings.TrimSpace(text)
+		}
+	}
+	if rm.Comment != "" {
+		comment = rm.Comment + " " + comment
+	}
+	rm.Comment = comment
+}
+
+// ReadGameFile reads every game of the record file at path
+func ReadGameFile(path string) ([]*Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var games []*Game
+	gr := NewGameReader(f)
+	for {
+		g, err := gr.Read()
+		if errors.Is(err, io.EOF) {
+			return games, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		games = append(games, g)
+	}
+}
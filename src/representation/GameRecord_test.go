@@ -0,0 +1,143 @@
+package representation
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+const twoGames = `[White "MiniMax depth 3"]
+[Black "Human"]
+[Date "2026.10.18"]
+[Variant "Morris-B"]
+[Result "*"]
+
+1. a0 g0 2. a3 {[%eval 1] threatens
+a6} g3 3. a6xg0 {mill} *
+
+[White "A"]
+[Black "B"]
+[Position "WxxxxxWxxxxBxxxxxxWxx B 0 0 4 20"]
+[Result "0-1"]
+
+20... g3-g0 21. a0-b1 0-1
+`
+
+func TestGameReader(t *testing.T) {
+	gr := NewGameReader(strings.NewReader(twoGames))
+
+	g, err := gr.Read()
+	if err != nil {
+		t.Fatalf("first game: %v", err)
+	}
+	if g.Header("Black") != "Human" || len(g.Moves) != 5 || g.Result != Unfinished {
+		t.Errorf("first game: %+v", g)
+	}
+	if rm := g.Moves[2]; !rm.HasEval || rm.Eval != 1 || rm.Comment != "threatens a6" || rm.Line != 7 {
+		t.Errorf("third move annotations: %+v", rm)
+	}
+	if rm := g.Moves[4]; rm.Move.String() != "a6xg0" || rm.Comment != "mill" || rm.Line != 8 {
+		t.Errorf("fifth move: %+v", rm)
+	}
+	if p, err := ValidateGame(g); err != nil || p.String() != "WxxxxxWxxxxBxxxxxxWxx B 6 7 0 3" {
+		t.Errorf("ValidateGame(first game) = %s, %v", p.String(), err)
+	}
+
+	g, err = gr.Read()
+	if err != nil {
+		t.Fatalf("second game: %v", err)
+	}
+	if g.Start.SideToMove != Black || len(g.Moves) != 2 || g.Result != BlackWins {
+		t.Errorf("second game: %+v", g)
+	}
+
+	if _, err := gr.Read(); err != io.EOF {
+		t.Errorf("after the last game: %v, want io.EOF", err)
+	}
+}
+
+// Test that the writer's output reads back as the same games
+func TestGameWriterRoundTrip(t *testing.T) {
+	var games []*Game
+	gr := NewGameReader(strings.NewReader(twoGames))
+	for g, err := gr.Read(); err == nil; g, err = gr.Read() {
+		games = append(games, g)
+	}
+
+	var buf bytes.Buffer
+	gw := NewGameWriter(&buf)
+	for _, g := range games {
+		if err := gw.Write(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gr = NewGameReader(&buf)
+	for i, want := range games {
+		got, err := gr.Read()
+		if err != nil {
+			t.Fatalf("game %d: %v", i, err)
+		}
+		if len(got.Moves) != len(want.Moves) || got.Result != want.Result || got.Start != want.Start || len(got.Headers) != len(want.Headers) {
+			t.Fatalf("game %d: read back %+v, want %+v", i, got, want)
+		}
+		for j := range got.Moves {
+			got.Moves[j].Line, want.Moves[j].Line = 0, 0
+			if got.Moves[j] != want.Moves[j] {
+				t.Errorf("game %d move %d: read back %+v, want %+v", i, j, got.Moves[j], want.Moves[j])
+			}
+		}
+	}
+}
+
+// Test that comments read back as written, and that one holding '}' is refused before anything is written
+func TestGameWriterComments(t *testing.T) {
+	g := NewGame("A", "B", "2026.10.18")
+	a0, _ := ParseMove("a0")
+	g.Moves = []RecordedMove{{Move: a0, Comment: "a {brace and [%eval] bracket"}}
+	var buf bytes.Buffer
+	if err := NewGameWriter(&buf).Write(g); err != nil {
+		t.Fatal(err)
+	}
+	read, err := NewGameReader(&buf).Read()
+	if err != nil || len(read.Moves) != 1 || read.Moves[0].Comment != g.Moves[0].Comment {
+		t.Errorf("read back %+v, %v", read, err)
+	}
+
+	buf.Reset()
+	g.Moves[0].Comment = "closed} early"
+	if err := NewGameWriter(&buf).Write(g); err == nil || buf.Len() != 0 {
+		t.Errorf("Write = %v, wrote %q", err, buf.String())
+	}
+}
+
+func TestValidateGameReportsLine(t *testing.T) {
+	record := "[Result \"*\"]\n\n1. a0 g0\n2. a0 g3 *\n"
+	g, err := NewGameReader(strings.NewReader(record)).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ValidateGame(g)
+	var illegal *IllegalMoveError
+	if !errors.As(err, &illegal) || illegal.Line != 4 || illegal.Ply != 2 {
+		t.Errorf("ValidateGame = %v, want an illegal move at line 4, ply 2", err)
+	}
+}
+
+func TestGameReaderSyntaxErrors(t *testing.T) {
+	for _, record := range []string{
+		"[White Human]\n\n1. a0 *\n",
+		"[Result \"*\"]\n\n1. a0 h9 *\n",
+		"[Result \"*\"]\n\n1. a0 g0\n",
+		"[Result \"*\"]\n\n{early} 1. a0 *\n",
+	} {
+		_, err := NewGameReader(strings.NewReader(record)).Read()
+		var syntax *RecordSyntaxError
+		if !errors.As(err, &syntax) {
+			t.Errorf("Read(%q) = %v, want a RecordSyntaxError", record, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package representation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- Inferring moves from consecutive board states
+
+// NoMoveError reports that no legal move of Color leads from Before to After, with the reason why
+type NoMoveError struct {
+	Before MorrisBoard
+	After  MorrisBoard
+	Color  int
+	Reason string
+}
+
+func (e *NoMoveError) Error() string {
+	return fmt.Sprintf("no %s move leads from %s to %s: %s", colorName(e.Color), e.Before.String(), e.After.String(), e.Reason)
+}
+
+// AmbiguousMoveError reports that more than one legal move leads from Before to After
+type AmbiguousMoveError struct {
+	Before MorrisBoard
+	After  MorrisBoard
+	Moves  []Move
+}
+
+func (e *AmbiguousMoveError) Error() string {
+	names := make([]string, len(e.Moves))
+	for i, m := range e.Moves {
+		names[i] = m.String()
+	}
+	return fmt.Sprintf("%d moves lead from %s to %s: %s", len(e.Moves), e.Before.String(), e.After.String(), strings.Join(names, ", "))
+}
+
+func colorName(color int) string {
+	if color == Black {
+		return "Black"
+	}
+	return "White"
+}
+
+func squareList(positions []int) string {
+	names := make([]string, len(positions))
+	for i, position := range positions {
+		names[i] = SquareName(position)
+	}
+	return strings.Join(names, ", ")
+}
+
+// InferMove returns the legal move of color, a placement, slide or hop with the removal it makes if any,
+// that turns before into after. It returns a NoMoveError explaining why no move fits, or an
+// AmbiguousMoveError if several do.
+func InferMove(before MorrisBoard, after MorrisBoard, color int) (Move, error) {
+	none := Move{From: NoSquare, To: NoSquare, Remove: NoSquare}
+
+	var candidates []Move
+	for _, phase := range []int{Opening, MidgameEndgame} {
+		for _, next := range GenerateMoves(&before, color, phase) {
+			if *next == after {
+				candidates = append(candidates, MoveBetween(&before, next, color))
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 1:
+		return candidates[0], nil
+	case 0:
+		return none, &NoMoveError{Before: before, After: after, Color: color, Reason: explainNoMove(before, after, color)}
+	default:
+		return none, &AmbiguousMoveError{Before: before, After: after, Moves: candidates}
+	}
+}
+
+// explainNoMove describes what is wrong with the change from before to after as a move of color
+func explainNoMove(before MorrisBoard, after MorrisBoard, color int) string {
+	opponent := 3 - color
+	var arrived, left, removed, other []int
+	for position := 0; position < 21; position++ {
+		was, is := before.GetPosition(position), after.GetPosition(position)
+		switch {
+		case was == is:
+		case was == Empty && is == color:
+			arrived = append(arrived, position)
+		case was == color && is == Empty:
+			left = append(left, position)
+		case was == opponent && is == Empty:
+			removed = append(removed, position)
+		default:
+			other = append(other, position)
+		}
+	}
+
+	switch {
+	case before == after:
+		return "the boards are identical"
+	case len(other) > 0:
+		return fmt.Sprintf("%s changed in a way no %s move can change it", squareList(other), colorName(color))
+	case len(arrived) != 1:
+		return fmt.Sprintf("%d %s pieces arrived (%s), a move brings exactly one", len(arrived), colorName(color), squareList(arrived))
+	case len(left) > 1:
+		return fmt.Sprintf("%d %s pieces left their squares (%s), a move moves at most one", len(left), colorName(color), squareList(left))
+	case len(removed) > 1:
+		return fmt.Sprintf("%d %s pieces were removed (%s), a mill removes one", len(removed), colorName(opponent), squareList(removed))
+	}
+
+	to := arrived[0]
+	pieces := 0
+	for position := 0; position < 21; position++ {
+		if before.GetPosition(position) == color {
+			pieces++
+		}
+	}
+	if len(left) == 1 {
+		adjacent := false
+		for _, n := range Neighbors(left[0]) {
+			adjacent = adjacent || n == to
+		}
+		if !adjacent && pieces != 3 {
+			return fmt.Sprintf("%s and %s are not adjacent, and %s can only hop with exactly 3 pieces, not %d",
+				SquareName(left[0]), SquareName(to), colorName(color), pieces)
+		}
+	}
+
+	// The piece arrived legally, so what is left to get wrong is the removal
+	moved := before
+	if len(left) == 1 {
+		moved.SetPosition(left[0], Empty)
+	}
+	closes := CloseMill(to, &moved, color)
+	switch {
+	case closes && len(removed) == 0:
+		return fmt.Sprintf("%s closes a mill but no %s piece was removed", SquareName(to), colorName(opponent))
+	case !closes && len(removed) == 1:
+		return fmt.Sprintf("%s was removed but %s does not close a mill", SquareName(removed[0]), SquareName(to))
+	case closes && len(removed) == 1:
+		return fmt.Sprintf("%s stands in a mill while other %s pieces do not", SquareName(removed[0]), colorName(opponent))
+	}
+	return "no legal move matches the change"
+}
+
+// GameFromBoards converts a transcript of consecutive board states, such as the output files of the
+// legacy CLIs, into a game record. Moves alternate starting with first; the first board is completed
+// with LegacyPosition for the given phase.
+func GameFromBoards(boards []MorrisBoard, first int, phase int) (*Game, error) {
+	g := &Game{Result: Unfinished}
+	g.SetHeader("Variant", "Morris-B")
+	g.SetHeader("Result", Unfinished)
+	if len(boards) == 0 {
+		g.Start = StartPosition()
+		return g, nil
+	}
+
+	g.Start = LegacyPosition(boards[0], first, phase)
+	g.SetHeader("Position", g.Start.String())
+	p := g.Start
+	for i := 1; i < len(boards); i++ {
+		m, err := InferMove(boards[i-1], boards[i], p.SideToMove)
+		if err != nil {
+			return g, fmt.Errorf("board %d: %w", i+1, err)
+		}
+		if p, err = p.Play(m); err != nil {
+			return g, fmt.Errorf("board %d: %w", i+1, err)
+		}
+		g.Moves = append(g.Moves, RecordedMove{Move: m})
+	}
+	return g, nil
+}
@@ -0,0 +1,69 @@
+package representation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mustParseBoard(t *testing.T, s string) MorrisBoard {
+	t.Helper()
+	b, err := ParseBoard(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// Test that every generated board state is inferred back as the move that produced it
+func TestInferMoveRecoversGeneratedMoves(t *testing.T) {
+	for _, tc := range perftTable {
+		board := mustParseBoard(t, tc.board)
+		for _, m := range LegalMoves(&board, tc.color, tc.phase) {
+			got, err := InferMove(board, *board.Apply(m, tc.color), tc.color)
+			if err != nil || got != m {
+				t.Errorf("InferMove(%s, %s) = %s, %v", tc.board, m.String(), got.String(), err)
+			}
+		}
+	}
+}
+
+func TestInferMoveExplains(t *testing.T) {
+	tests := []struct {
+		before, after string
+		color         int
+		reason        string
+	}{
+		{"xxxxxxWxxBxxxxxxBxWxx", "xxxxxxWxxBxxxxxxBxWxx", White, "identical"},
+		{"xxxxxxWxxBxxxxxxBxWxx", "WWxxxxWxxBxxxxxxBxWxx", White, "2 White pieces arrived"},
+		{"xxxxxxWxxBxxxxxxBxWxx", "BxxxxxWxxBxxxxxxBxWxx", White, "a0 changed"},
+		{"WxWxxxWxxBxxBxxxBxWBx", "xxWxxxWxxBxxBxxxBxWBW", White, "not adjacent"},
+		{"xxxxxxWxxBxxxxxxBxWxx", "WxxxxxWxxBxxxxxxBxWxx", White, "closes a mill but no Black piece was removed"},
+		{"xxxxxxWxxBxxxxxxBxWxx", "xWxxxxWxxxxxxxxxBxWxx", White, "does not close a mill"},
+		{"xxxxxxWxxBxxxxBBBxWxx", "WxxxxxWxxBxxxxxxBxWxx", White, "2 Black pieces were removed"},
+	}
+	for _, tc := range tests {
+		_, err := InferMove(mustParseBoard(t, tc.before), mustParseBoard(t, tc.after), tc.color)
+		var noMove *NoMoveError
+		if !errors.As(err, &noMove) || !strings.Contains(noMove.Reason, tc.reason) {
+			t.Errorf("InferMove(%s, %s) = %v, want a reason containing %q", tc.before, tc.after, err, tc.reason)
+		}
+	}
+}
+
+func TestGameFromBoards(t *testing.T) {
+	var boards []MorrisBoard
+	for _, s := range []string{"xxxxxxWxxBxxxxxxBxWxx", "xxxxxxWxxBxxxBxxBxWxx", "WxxxxxWxxBxxxxxxBxWxx"} {
+		boards = append(boards, mustParseBoard(t, s))
+	}
+	g, err := GameFromBoards(boards, Black, Opening)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Moves) != 2 || g.Moves[0].Move.String() != "d4" || g.Moves[1].Move.String() != "a0xd4" {
+		t.Errorf("GameFromBoards moves = %+v", g.Moves)
+	}
+	if _, err := ValidateGame(g); err != nil {
+		t.Errorf("ValidateGame: %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package representation
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// --- Legacy searchers
+//
+// The searchers of the six legacy search CLIs, with the phase as a parameter, shared by the binaries and
+// the commands that reproduce them. Every ply is played in the given phase, White maximizes and Black minimizes, and a position without
+// moves keeps the initial estimate of the side to move and an empty board as its best move.
+
+// LegacyMiniMax searches the board to the given depth with plain minimax and returns the best board after
+// player's move, the number of positions evaluated and the estimate
+func LegacyMiniMax(board *MorrisBoard, player int, depth int, phase int, evaluator Evaluator) (*MorrisBoard, int, int) {
+	return legacyMiniMax(board, player, depth, phase, player == White, 0, evaluator)
+}
+
+func legacyMiniMax(board *MorrisBoard, player int, depth int, phase int, maximizingPlayer bool, nodesEvaluated int, evaluator Evaluator) (*MorrisBoard, int, int) {
+	if depth == 0 {
+		nodesEvaluated++
+		return board, nodesEvaluated, evaluator.Evaluate(board, phase, player)
+	}
+
+	var bestBoard *MorrisBoard
+	bestEstimate := math.MaxInt32
+	if maximizingPlayer {
+		bestEstimate = math.MinInt32
+	}
+	for _, move := range GenerateMoves(board, player, phase) {
+		_, evaluated, estimate := legacyMiniMax(move, 3-player, depth-1, phase, !maximizingPlayer, nodesEvaluated, evaluator)
+		nodesEvaluated = evaluated
+		if (maximizingPlayer && estimate > bestEstimate) || (!maximizingPlayer && estimate < bestEstimate) {
+			bestEstimate, bestBoard = estimate, move
+		}
+	}
+	if bestBoard == nil {
+		return &MorrisBoard{}, nodesEvaluated, bestEstimate
+	}
+	return bestBoard, nodesEvaluated, bestEstimate
+}
+
+// LegacyMiniMaxAB is LegacyMiniMax with alpha-beta pruning. It returns the same best board and estimate
+// and evaluates fewer positions.
+func LegacyMiniMaxAB(board *MorrisBoard, player int, depth int, phase int, evaluator Evaluator) (*MorrisBoard, int, int) {
+	return legacyAlphaBeta(board, player, depth, phase, math.MinInt32, math.MaxInt32, player == White, 0, evaluator)
+}
+
+func legacyAlphaBeta(board *MorrisBoard, player int, depth int, phase int, alpha int, beta int, maximizingPlayer bool, nodesEvaluated int, evaluator Evaluator) (*MorrisBoard, int, int) {
+	if depth == 0 {
+		nodesEvaluated++
+		return board, nodesEvaluated, evaluator.Evaluate(board, phase, player)
+	}
+
+	var bestBoard *MorrisBoard
+	bestEstimate := math.MaxInt32
+	if maximizingPlayer {
+		bestEstimate = math.MinInt32
+	}
+	for _, move := range GenerateMoves(board, player, phase) {
+		_, evaluated, estimate := legacyAlphaBeta(move, 3-player, depth-1, phase, alpha, beta, !maximizingPlayer, nodesEvaluated, evaluator)
+		nodesEvaluated = evaluated
+		if maximizingPlayer {
+			if estimate > bestEstimate {
+				bestEstimate, bestBoard = estimate, move
+			}
+			alpha = max(alpha, estimate)
+		} else {
+			if estimate < bestEstimate {
+				bestEstimate, bestBoard = estimate, move
+			}
+			beta = min(beta, estimate)
+		}
+		if beta <= alpha {
+			break // Cutoff
+		}
+	}
+	if bestBoard == nil {
+		return &MorrisBoard{}, nodesEvaluated, bestEstimate
+	}
+	return bestBoard, nodesEvaluated, bestEstimate
+}
+
+// --- Legacy search input and output
+
+// ReadLegacyPosition reads the input file of a legacy search, printing it and, if moves follow the
+// position, the position they lead to. A legacy board is played by color and completed for phase, an
+// extended position is played by its side to move. The second result tells whether the position was extended.
+func ReadLegacyPosition(out io.Writer, path string, color int, phase int) (Position, bool, error) {
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return Position{}, false, fmt.Errorf("failed to read input board file: %v", err)
+	}
+	fmt.Fprintf(out, "Input position: %s\n", strings.TrimSpace(string(input)))
+
+	extended := IsExtendedPosition(string(input))
+	position, moves, err := ParsePositionAndMoves(string(input), color, phase)
+	if err != nil {
+		return Position{}, false, fmt.Errorf("invalid input board %s: %v", path, err)
+	}
+	if len(moves) > 0 {
+		fmt.Fprintf(out, "Position after moves: %s\n", position.Format(extended))
+	}
+	return position, extended, nil
+}
+
+// LegacyOutput returns the output position of a search of p that chose best, reached by move: the board
+// string after a legacy board, the position after move in extended notation after an extended position. When
+// there is no move the board the searcher returned is kept.
+func LegacyOutput(p Position, extended bool, best *MorrisBoard, move Move) (string, error) {
+	if !extended || len(p.LegalMoves()) == 0 {
+		return best.String(), nil
+	}
+	next, err := p.Play(move)
+	if err != nil {
+		return "", fmt.Errorf("cannot play output move %s: %v", move.String(), err)
+	}
+	return next.String(), nil
+}
+
+// WriteLegacyResult prints the result of a search as the legacy binaries do, followed by the explanation if
+// any, and writes the output position to path unless it is empty
+func WriteLegacyResult(out io.Writer, path string, output string, move Move, nodes int, estimate int, explanation string) error {
+	fmt.Fprintf(out, "Output position: %s\n", output)
+	fmt.Fprintf(out, "Output move: %s\n", move.String())
+	fmt.Fprintf(out, "Positions evaluated by static estimation: %d\n", nodes)
+	fmt.Fprintf(out, "MINIMAX estimate: %d\n", estimate)
+	fmt.Fprint(out, explanation)
+
+	if path != "" {
+		if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+			return fmt.Errorf("failed to write output board file: %v", err)
+		}
+	}
+	return nil
+}
+
+// --- Legacy binaries
+
+// LegacySearch is a search of an input file by LegacyMiniMax or LegacyMiniMaxAB
+type LegacySearch struct {
+	Input     string // Position file
+	Output    string // File the output position is written to, none if empty
+	Side      int    // Side to move of a legacy board
+	Phase     int    // Phase of a legacy board, and of every ply of the search
+	OwnPhase  bool   // An extended position is searched in its own phase rather than rejected outside Phase
+	AlphaBeta bool   // Search with LegacyMiniMaxAB rather than LegacyMiniMax
+	Depth     int
+	Evaluator Evaluator
+	Explain   bool // Break the evaluation down at the root and at the leaf of the principal variation
+}
+
+// Run searches the position of the input file, prints the best move and writes the output position
+func (s LegacySearch) Run(out io.Writer) error {
+	position, extended, err := ReadLegacyPosition(out, s.Input, s.Side, s.Phase)
+	if err != nil {
+		return err
+	}
+	board, player, phase := &position.Board, position.SideToMove, s.Phase
+	if extended {
+		if s.OwnPhase {
+			phase = position.Phase()
+		}
+		if err := position.CheckPhase(phase); err != nil {
+			return fmt.Errorf("invalid input board %s: %v", s.Input, err)
+		}
+	}
+
+	search := LegacyMiniMax
+	if s.AlphaBeta {
+		search = LegacyMiniMaxAB
+	}
+	best, nodes, estimate := search(board, player, s.Depth, phase, s.Evaluator)
+	move := MoveBetween(board, best, player)
+	output, err := LegacyOutput(position, extended, best, move)
+	if err != nil {
+		return err
+	}
+
+	var explanation string
+	if s.Explain {
+		line := PrincipalVariation(board, player, s.Depth, phase, func(board *MorrisBoard, player int, depth int) (*MorrisBoard, int, int) {
+			return search(board, player, depth, phase, s.Evaluator)
+		})
+		explanation = ExplainLine(s.Evaluator, board, player, phase, line)
+	}
+	return WriteLegacyResult(out, s.Output, output, move, nodes, estimate, explanation)
+}
+
+// LegacyBinary is one of the six legacy search binaries. They differ only in their searcher, the phase they
+// play, the side a legacy board is played by and the name in their usage message.
+type LegacyBinary struct {
+	AlphaBeta bool
+	Phase     int
+	Side      int
+	Usage     string // Program name in the usage message
+}
+
+// LegacyBinaries are the legacy search binaries by name
+var LegacyBinaries = map[string]LegacyBinary{
+	"MiniMaxOpening":      {false, Opening, White, "MiniMaxOpening"},
+	"ABOpening":           {true, Opening, White, "MiniMaxOpening"},
+	"MiniMaxOpeningBlack": {false, Opening, Black, "MiniMaxOpening"},
+	"MiniMaxGame":         {false, MidgameEndgame, White, "MiniMaxMid"},
+	"ABGame":              {true, MidgameEndgame, White, "MiniMaxMid"},
+	"MiniMaxGameBlack":    {false, MidgameEndgame, Black, "MiniMaxMid"},
+}
+
+// Run behaves as the binary with the given command-line arguments, the program name left out:
+// [--eval NAME] [--weights FILE] [--explain] <input_file> <output_file> <depth>, the options anywhere
+func (b LegacyBinary) Run(args []string, out io.Writer) error {
+	// Take the optional --eval NAME, --weights FILE and --explain out of the command-line arguments
+	evaluator, args, err := ParseEvaluatorFlag(args)
+	if err != nil {
+		return err
+	}
+	explain, args := ParseExplainFlag(args)
+	if len(args) < 3 {
+		return fmt.Errorf("usage: %s [--eval NAME] [--weights FILE] [--explain] <input_file> <output_file> <depth>", b.Usage)
+	}
+	depth, err := strconv.Atoi(args[2])
+	if err != nil {
+		return fmt.Errorf("invalid depth: %s", args[2])
+	}
+
+	return LegacySearch{
+		Input: args[0], Output: args[1], Side: b.Side, Phase: b.Phase,
+		AlphaBeta: b.AlphaBeta, Depth: depth, Evaluator: evaluator, Explain: explain,
+	}.Run(out)
+}
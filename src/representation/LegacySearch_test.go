@@ -0,0 +1,75 @@
+package representation
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"representation/reference"
+	"testing"
+)
+
+// Test that pruning keeps the best board and estimate of plain minimax and evaluates fewer positions
+func TestLegacyMiniMaxABMatchesMiniMax(t *testing.T) {
+	e := standardEvaluator{}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 60; i++ {
+		phase, placements, moves := Opening, rng.Intn(12), 0
+		if i%2 == 1 {
+			phase, placements, moves = MidgameEndgame, 18, rng.Intn(20)
+		}
+		s, _ := reference.RandomPosition(rng, placements, moves)
+		board := MorrisBoardFromString(s)
+		for _, player := range []int{White, Black} {
+			for depth := 1; depth <= 3; depth++ {
+				best, nodes, estimate := LegacyMiniMax(board, player, depth, phase, e)
+				bestAB, nodesAB, estimateAB := LegacyMiniMaxAB(board, player, depth, phase, e)
+				if *bestAB != *best || estimateAB != estimate || nodesAB > nodes {
+					t.Fatalf("%s, player %d, depth %d: alpha-beta %s %d (%d nodes), minimax %s %d (%d nodes)",
+						s, player, depth, bestAB, estimateAB, nodesAB, best, estimate, nodes)
+				}
+			}
+		}
+	}
+}
+
+// Test the count of evaluated positions of a full tree
+func TestLegacyMiniMaxNodes(t *testing.T) {
+	e := standardEvaluator{}
+	board := MorrisBoardFromString("xxxxxxxxxxxxxxxxxxxxx")
+	if _, nodes, estimate := LegacyMiniMax(board, White, 2, Opening, e); nodes != 420 || estimate != 0 {
+		t.Errorf("LegacyMiniMax = %d nodes, estimate %d, want 420 and 0", nodes, estimate)
+	}
+}
+
+// Test that an extended input is checked against the searcher's phase and its output played from it
+func TestLegacyInputAndOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input.txt")
+	os.WriteFile(path, []byte("WWWxBBxxxxxxxxxxxxxxx W 0 0 0 20\n"), 0644)
+	search := LegacySearch{Input: path, Side: White, Phase: Opening, Depth: 2, Evaluator: standardEvaluator{}}
+	if err := search.Run(io.Discard); err == nil {
+		t.Error("a midgame position was searched in the opening")
+	}
+	search.OwnPhase = true
+	if err := search.Run(io.Discard); err != nil {
+		t.Errorf("a midgame position was not searched in its own phase: %v", err)
+	}
+	p, extended, err := ReadLegacyPosition(io.Discard, path, White, MidgameEndgame)
+	if err != nil || !extended {
+		t.Fatalf("ReadLegacyPosition = %v, %v", extended, err)
+	}
+
+	best, _, _ := LegacyMiniMaxAB(&p.Board, White, 2, MidgameEndgame, standardEvaluator{})
+	move := MoveBetween(&p.Board, best, White)
+	next, _ := p.Play(move)
+	if output, err := LegacyOutput(p, true, best, move); err != nil || output != next.String() {
+		t.Errorf("LegacyOutput = %q, %v, want %s", output, err, next)
+	}
+	if output, err := LegacyOutput(p, false, best, move); err != nil || output != best.String() {
+		t.Errorf("LegacyOutput of a legacy board = %q, %v", output, err)
+	}
+	placement, _ := ParseMove("d6")
+	if _, err := LegacyOutput(p, true, best, placement); err == nil {
+		t.Error("LegacyOutput played a placement without pieces in hand")
+	}
+}
@@ -0,0 +1,173 @@
+package representation
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// --- Engine matches
+
+// DrawHalfmoves is the halfmove clock at which a game is drawn, as neither side has placed or removed a
+// piece for that many moves
+const DrawHalfmoves = 50
+
+// Engine search algorithms
+const (
+	AlgorithmAlphaBeta = "ab"
+	AlgorithmMiniMax   = "minimax" // Same moves as AlgorithmAlphaBeta, more slowly
+)
+
+// Engine is a searcher with a fixed evaluator, searching to a fixed depth or for a fixed time
+type Engine struct {
+	Name      string
+	Evaluator Evaluator
+	Depth     int           // Search depth, or deepest iteration of a timed search, unbounded if 0
+	Algorithm string        // AlgorithmAlphaBeta if empty
+	MoveTime  time.Duration // If set, the engine deepens its search until this time is spent on a move
+}
+
+// NewEngine returns an engine using the evaluator registered under evalName, named after it and the depth
+func NewEngine(evalName string, depth int) (Engine, error) {
+	e, err := LookupEvaluator(evalName)
+	if err != nil {
+		return Engine{}, err
+	}
+	return Engine{Name: fmt.Sprintf("%s depth %d", evalName, depth), Evaluator: e, Depth: depth}, nil
+}
+
+// Search runs the engine's search on p
+func (e Engine) Search(p Position) SearchResult {
+	miniMax := e.Algorithm == AlgorithmMiniMax
+	if e.MoveTime > 0 {
+		return SearchLimits(p, e.Evaluator, Limits{Depth: e.Depth, MoveTime: e.MoveTime, MiniMax: miniMax})
+	}
+	s := &searcher{evaluator: e.Evaluator, miniMax: miniMax}
+	estimate, pv := s.alphaBeta(p, e.Depth, 0, math.MinInt32, math.MaxInt32)
+	return s.result(estimate, pv, e.Depth)
+}
+
+// PlayGame plays a game between two engines from start and returns its record, the evaluation of every
+// move included. The game is drawn once the halfmove clock reaches DrawHalfmoves or after maxPlies plies.
+func PlayGame(white Engine, black Engine, start Position, maxPlies int) *Game {
+	g := &Game{Start: start, Result: Unfinished}
+	g.SetHeader("White", white.Name)
+	g.SetHeader("Black", black.Name)
+	g.SetHeader("Variant", "Morris-B")
+	g.SetHeader("Result", Unfinished)
+	if start != StartPosition() {
+		g.SetHeader("Position", start.String())
+	}
+
+	p := start
+	for ply := 0; ; ply++ {
+		if outcome := p.Outcome(); outcome != Unfinished {
+			g.SetResult(outcome)
+			return g
+		}
+		if p.HalfmoveClock >= DrawHalfmoves || ply >= maxPlies {
+			g.SetResult(Draw)
+			return g
+		}
+
+		engine := white
+		if p.SideToMove == Black {
+			engine = black
+		}
+		result := engine.Search(p)
+		next, err := p.Play(result.Move)
+		if err != nil {
+			// An engine returning an illegal move forfeits the game
+			g.SetHeader("Termination", fmt.Sprintf("illegal move %s by %s", result.Move.String(), engine.Name))
+			g.SetResult(lossFor(p.SideToMove))
+			return g
+		}
+		g.Moves = append(g.Moves, RecordedMove{Move: result.Move, Eval: result.Estimate, HasEval: true})
+		p = next
+	}
+}
+
+// MatchResult counts the games of a match from the point of view of its first engine
+type MatchResult struct {
+	Wins, Draws, Losses int
+	Games               []*Game
+}
+
+// Score returns the points of the first engine, a win counting 1 and a draw 1/2, as a fraction of the games
+func (r MatchResult) Score() float64 {
+	games := r.Wins + r.Draws + r.Losses
+	if games == 0 {
+		return 0.5
+	}
+	return (float64(r.Wins) + float64(r.Draws)/2) / float64(games)
+}
+
+// Count adds a finished game to the result, aWhite telling whether the first engine played White
+func (r *MatchResult) Count(g *Game, aWhite bool) {
+	r.Games = append(r.Games, g)
+	switch {
+	case g.Result == Draw:
+		r.Draws++
+	case (g.Result == WhiteWins) == aWhite:
+		r.Wins++
+	default:
+		r.Losses++
+	}
+}
+
+// PlayMatch plays two games from every opening, a with White then a with Black
+func PlayMatch(a Engine, b Engine, openings []Position, maxPlies int) MatchResult {
+	var r MatchResult
+	for _, opening := range openings {
+		for _, aWhite := range []bool{true, false} {
+			var g *Game
+			if aWhite {
+				g = PlayGame(a, b, opening, maxPlies)
+			} else {
+				g = PlayGame(b, a, opening, maxPlies)
+			}
+			r.Count(g, aWhite)
+		}
+	}
+	return r
+}
+
+// OpeningSuite returns n different positions reached by playing the given number of random plies from the
+// start position, so that engines with deterministic searches play different games
+func OpeningSuite(rng *rand.Rand, n int, plies int) []Position {
+	seen := map[Position]bool{}
+	var suite []Position
+	for attempts := 0; len(suite) < n && attempts < 100*n; attempts++ {
+		p := StartPosition()
+		for ply := 0; ply < plies && p.Outcome() == Unfinished; ply++ {
+			moves := p.LegalMoves()
+			p = p.played(moves[rng.Intn(len(moves))])
+		}
+		if !seen[p] && p.Outcome() == Unfinished {
+			seen[p] = true
+			suite = append(suite, p)
+		}
+	}
+	return suite
+}
+
+// BalancedOpeningSuite returns n different openings as OpeningSuite does, keeping only those where both sides
+// have the same number of pieces and the side to move cannot force a capture within two plies, so that neither
+// color starts with an advantage the engines did not earn
+func BalancedOpeningSuite(rng *rand.Rand, n int, plies int) []Position {
+	seen := map[Position]bool{}
+	var suite []Position
+	for attempts := 0; len(suite) < n && attempts < 100; attempts++ {
+		for _, p := range OpeningSuite(rng, n, plies) {
+			if len(suite) == n || seen[p] || p.Pieces(White) != p.Pieces(Black) {
+				continue
+			}
+			seen[p] = true
+			if Search(p, 2, materialEvaluator{}).Estimate == StaticEstimateOpeningNaive(&p.Board) {
+				suite = append(suite, p)
+			}
+		}
+	}
+	return suite
+}
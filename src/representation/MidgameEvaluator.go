@@ -0,0 +1,98 @@
+package representation
+
+// --- Midgame evaluator
+//
+// MidgameEvaluator is StaticEstimateMidgameEndgame with its weights taken out, so that tuners can replace
+// the hand-picked 1000 per piece and 1 per Black move. With DefaultMidgameWeights it returns the same
+// estimates as StaticEstimateMidgameEndgame.
+
+// MidgameWeights are the weights of the terms of MidgameEvaluator
+type MidgameWeights struct {
+	Material   int // Per White piece minus Black piece
+	WhiteMoves int // Per White move
+	BlackMoves int // Per Black move
+	Mills      int // Per White closed mill minus Black closed mill
+}
+
+// DefaultMidgameWeights are the weights of StaticEstimateMidgameEndgame
+var DefaultMidgameWeights = MidgameWeights{Material: 1000, WhiteMoves: 0, BlackMoves: -1, Mills: 0}
+
+// MidgameEvaluator evaluates midgame and endgame positions by the terms of MidgameWeights, keeping the
+// overrides of StaticEstimateMidgameEndgame for a side with 2 pieces and a Black side without moves. In the
+// opening it defers to StaticEstimateOpeningNaive.
+type MidgameEvaluator struct {
+	Weights MidgameWeights
+}
+
+func init() {
+	RegisterEvaluator("midgame", &MidgameEvaluator{Weights: DefaultMidgameWeights})
+}
+
+// Evaluate implements Evaluator. It computes the same total as Explain without building the terms, as it is
+// called at every leaf of a search.
+func (e *MidgameEvaluator) Evaluate(board *MorrisBoard, phase int, sideToMove int) int {
+	if phase == Opening {
+		return StaticEstimateOpeningNaive(board)
+	}
+
+	whitePieces, blackPieces := countPieces(board, White), countPieces(board, Black)
+	blackMoves := len(GenerateMovesMidgameEndgame(board, Black))
+	switch {
+	case blackPieces <= 2:
+		return 10000
+	case whitePieces <= 2:
+		return -10000
+	case blackMoves == 0:
+		return 10000
+	}
+
+	w := e.Weights
+	score := w.Material*(whitePieces-blackPieces) + w.BlackMoves*blackMoves
+	if w.WhiteMoves != 0 {
+		score += w.WhiteMoves * len(GenerateMovesMidgameEndgame(board, White)) // Only generated when it counts
+	}
+	if w.Mills != 0 {
+		score += w.Mills * (countMills(board, White) - countMills(board, Black))
+	}
+	return score
+}
+
+// Explain implements Explainer
+func (e *MidgameEvaluator) Explain(board *MorrisBoard, phase int, sideToMove int) Explanation {
+	if phase == Opening {
+		return explainOpeningNaive(board)
+	}
+
+	var x Explanation
+	whitePieces, blackPieces := countPieces(board, White), countPieces(board, Black)
+	whiteMoves := 0
+	if e.Weights.WhiteMoves != 0 {
+		whiteMoves = len(GenerateMovesMidgameEndgame(board, White)) // Only generated when it counts
+	}
+	blackMoves := len(GenerateMovesMidgameEndgame(board, Black))
+	x.add("piece difference", whitePieces-blackPieces, e.Weights.Material)
+	x.add("White moves", whiteMoves, e.Weights.WhiteMoves)
+	x.add("Black moves", blackMoves, e.Weights.BlackMoves)
+	x.add("mill difference", countMills(board, White)-countMills(board, Black), e.Weights.Mills)
+
+	switch {
+	case blackPieces <= 2:
+		x.Override, x.Total = "Black has 2 or fewer pieces", 10000
+	case whitePieces <= 2:
+		x.Override, x.Total = "White has 2 or fewer pieces", -10000
+	case blackMoves == 0:
+		x.Override, x.Total = "Black has no moves", 10000
+	}
+	return x
+}
+
+// Params implements Tunable
+func (e *MidgameEvaluator) Params() []Param {
+	w := e.Weights
+	return []Param{{"Material", w.Material}, {"WhiteMoves", w.WhiteMoves}, {"BlackMoves", w.BlackMoves}, {"Mills", w.Mills}}
+}
+
+// WithParams implements Tunable
+func (e *MidgameEvaluator) WithParams(values []int) Tunable {
+	return &MidgameEvaluator{Weights: MidgameWeights{Material: values[0], WhiteMoves: values[1], BlackMoves: values[2], Mills: values[3]}}
+}
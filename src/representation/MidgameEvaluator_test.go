@@ -0,0 +1,50 @@
+package representation
+
+import (
+	"math/rand"
+	"representation/reference"
+	"testing"
+)
+
+// Test that the midgame evaluator with the default weights is StaticEstimateMidgameEndgame
+func TestMidgameEvaluatorDefaults(t *testing.T) {
+	e := &MidgameEvaluator{Weights: DefaultMidgameWeights}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		s, _ := reference.RandomPosition(rng, 18, rng.Intn(40))
+		board := MorrisBoardFromString(s)
+		if got, want := e.Evaluate(board, MidgameEndgame, White), StaticEstimateMidgameEndgame(board); got != want {
+			t.Fatalf("%s: got %d, want %d", s, got, want)
+		}
+		if got, want := e.Evaluate(board, Opening, White), StaticEstimateOpeningNaive(board); got != want {
+			t.Fatalf("%s in the opening: got %d, want %d", s, got, want)
+		}
+	}
+}
+
+// Test that Evaluate, which skips the terms, totals as Explain does, overrides included
+func TestMidgameEvaluateMatchesExplain(t *testing.T) {
+	e := &MidgameEvaluator{Weights: MidgameWeights{Material: 7, WhiteMoves: 5, BlackMoves: -3, Mills: 11}}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		s, _ := reference.RandomPosition(rng, 18, rng.Intn(40))
+		board := MorrisBoardFromString(s)
+		for _, phase := range []int{Opening, MidgameEndgame} {
+			if got, want := e.Evaluate(board, phase, White), e.Explain(board, phase, White).Total; got != want {
+				t.Fatalf("%s in phase %d: Evaluate %d, Explain %d", s, phase, got, want)
+			}
+		}
+	}
+}
+
+// Test that every weight takes effect
+func TestMidgameEvaluatorWeights(t *testing.T) {
+	b := mustParseBoard(t, "WWWWWxxBxxBxxBxxxBxBx") // White has the mill a0-b1-c2, Black none
+	board := &b
+	whiteMoves, blackMoves := len(GenerateMovesMidgameEndgame(board, White)), len(GenerateMovesMidgameEndgame(board, Black))
+	e := &MidgameEvaluator{Weights: MidgameWeights{Material: 7, WhiteMoves: 5, BlackMoves: -3, Mills: 11}}
+	want := 7*(5-5) + 5*whiteMoves - 3*blackMoves + 11*(1-0)
+	if got := e.Evaluate(board, MidgameEndgame, White); got != want {
+		t.Errorf("Evaluate = %d, want %d", got, want)
+	}
+}
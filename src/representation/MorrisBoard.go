@@ -1,5 +1,10 @@
 package representation
 
+import (
+	"fmt"
+	"strings"
+)
+
 // --- Helpers
 func updateHalf(position int, half uint32, state int) uint32 {
 	bitPosition := uint(position * 2)
@@ -39,15 +44,35 @@ func (b *MorrisBoard) GetPosition(position int) int {
 	return int((b.secondHalf >> uint((position-16)*2)) & 3)
 }
 
-// MorrisBoardFromString creates a MorrisBoard from a string representation
-func MorrisBoardFromString(boardStr string) *MorrisBoard {
-	if len(boardStr) != 21 {
-		return nil // Board string must have exactly 21 characters
-	}
+// BoardLengthError reports a board string that does not describe exactly 21 positions
+type BoardLengthError struct {
+	Length int // Number of characters found, surrounding whitespace excluded
+}
+
+func (e *BoardLengthError) Error() string {
+	return fmt.Sprintf("board must have 21 positions, got %d", e.Length)
+}
 
-	board := &MorrisBoard{}
+// BoardCharError reports a character of a board string that is not one of 'x', 'W', 'B' or '-'
+type BoardCharError struct {
+	Char  rune // Offending character
+	Index int  // Board position it was found at
+}
 
-	for i, char := range boardStr {
+func (e *BoardCharError) Error() string {
+	return fmt.Sprintf("invalid character %q at position %d, want one of x, W, B or -", e.Char, e.Index)
+}
+
+// ParseBoard creates a MorrisBoard from a string representation, the inverse of String.
+// Surrounding whitespace such as the trailing newline of a board file is ignored.
+func ParseBoard(s string) (MorrisBoard, error) {
+	var board MorrisBoard
+	squares := []rune(strings.TrimSpace(s))
+	if len(squares) != 21 {
+		return board, &BoardLengthError{Length: len(squares)} // Board string must have exactly 21 characters
+	}
+
+	for i, char := range squares {
 		switch char {
 		case 'x':
 			board.SetPosition(i, Empty)
@@ -55,12 +80,23 @@ func MorrisBoardFromString(boardStr string) *MorrisBoard {
 			board.SetPosition(i, White)
 		case 'B':
 			board.SetPosition(i, Black)
+		case '-':
+			board.SetPosition(i, Unused)
 		default:
-			return nil // Invalid character in board string
+			return MorrisBoard{}, &BoardCharError{Char: char, Index: i} // Invalid character in board string
 		}
 	}
 
-	return board
+	return board, nil
+}
+
+// MorrisBoardFromString creates a MorrisBoard from a string representation, or returns nil if ParseBoard rejects it
+func MorrisBoardFromString(boardStr string) *MorrisBoard {
+	board, err := ParseBoard(boardStr)
+	if err != nil {
+		return nil
+	}
+	return &board
 }
 
 // String returns a string representation of the MorrisBoard state
@@ -100,6 +136,31 @@ func (b *MorrisBoard) InvertColors() *MorrisBoard {
 	return flippedBoard
 }
 
+// mills defines the mill formations as arrays of positions, each listed once per position it contains
+var mills = [][]int{
+	{0, 6, 18}, {0, 2, 4}, //   0 === a0
+	{1, 11, 20},           //   1 === g0
+	{2, 0, 4}, {2, 7, 15}, //   2 === b1
+	{3, 10, 17},           //   3 === f1
+	{4, 2, 0}, {4, 8, 12}, //   4 === c2
+	{5, 9, 14},            //   5 === e2
+	{6, 0, 18}, {6, 7, 8}, //   6 === a3
+	{7, 2, 15}, {7, 6, 8}, //   7 === b3
+	{8, 4, 12}, {8, 6, 7}, //   8 === c3
+	{9, 5, 14}, {9, 10, 11}, //   9 === e3
+	{10, 3, 17}, {10, 9, 11}, // 10 === f3
+	{11, 1, 20}, {11, 9, 10}, // 11 === g3
+	{12, 4, 8}, {12, 13, 14}, // 12 === c4
+	{13, 12, 14}, {13, 16, 19}, // 13 === d4
+	{14, 12, 13}, {14, 5, 9}, // 14 === e4
+	{15, 7, 2}, {15, 16, 17}, // 15 === b5
+	{16, 13, 19}, {16, 15, 17}, // 16 === d5
+	{17, 3, 10}, {17, 15, 16}, // 17 === f5
+	{18, 0, 6}, {18, 19, 20}, // 18 === a6
+	{19, 13, 16}, {19, 18, 20}, // 19 === d6
+	{20, 11, 1}, {20, 18, 19}, // 20 === g6
+}
+
 // CloseMill checks if placing a token at position j closes a mill on the board b
 func CloseMill(j int, b *MorrisBoard, color int) bool {
 	C := b.GetPosition(j) // Color of the token placed at position j (0, 1, 2)
@@ -108,31 +169,6 @@ func CloseMill(j int, b *MorrisBoard, color int) bool {
 		return false // if it is not empty, return false
 	}
 
-	// Define mill formations as arrays of positions
-	mills := [][]int{
-		{0, 6, 18}, {0, 2, 4}, //   0 === a0
-		{1, 11, 20},           //   1 === g0
-		{2, 0, 4}, {2, 7, 15}, //   2 === b1
-		{3, 10, 17},           //   3 === f1
-		{4, 2, 0}, {4, 8, 12}, //   4 === c2
-		{5, 9, 14},            //   5 === e2
-		{6, 0, 18}, {6, 7, 8}, //   6 === a3
-		{7, 2, 15}, {7, 6, 8}, //   7 === b3
-		{8, 4, 12}, {8, 6, 7}, //   8 === c3
-		{9, 5, 14}, {9, 10, 11}, //   9 === e3
-		{10, 3, 17}, {10, 9, 11}, // 10 === f3
-		{11, 1, 20}, {11, 9, 10}, // 11 === g3
-		{12, 4, 8}, {12, 13, 14}, // 12 === c4
-		{13, 12, 14}, {13, 16, 19}, // 13 === d4
-		{14, 12, 13}, {14, 5, 9}, // 14 === e4
-		{15, 7, 2}, {15, 16, 17}, // 15 === b5
-		{16, 13, 19}, {16, 15, 17}, // 16 === d5
-		{17, 3, 10}, {17, 15, 16}, // 17 === f5
-		{18, 0, 6}, {18, 19, 20}, // 18 === a6
-		{19, 13, 16}, {19, 18, 20}, // 19 === d6
-		{20, 11, 1}, {20, 18, 19}, // 20 === g6
-	}
-
 	// Iterate through the defined mills and check if the position j completes any of them
 	for _, mill := range mills {
 		var mil0, mil1, mil2 = mill[0], mill[1], mill[2]
@@ -145,29 +181,40 @@ func CloseMill(j int, b *MorrisBoard, color int) bool {
 	return false
 }
 
-func GenerateRemove(board *MorrisBoard, L *[]*MorrisBoard) {
-	// Check if removing each black piece does not close a mill, since normally you can only remove a black piece if it is not a mill
+// GenerateRemove adds to L every board state after color, having just closed a mill, removes an opponent piece
+func GenerateRemove(board *MorrisBoard, color int, L *[]*MorrisBoard) {
+	opponent := 3 - color
+	added := 0
+
+	// Check if removing each opponent piece does not close a mill, since normally you can only remove an opponent piece if it is not in a mill
 	for location := 0; location < 21; location++ {
-		if board.GetPosition(location) == Black {
+		if board.GetPosition(location) == opponent {
 			b := &MorrisBoard{firstHalf: board.firstHalf, secondHalf: board.secondHalf} // Create a copy of the board
-			b.SetPosition(location, Empty)                                              // Remove the black piece from the specified location
-			if !CloseMill(location, b, Black) {                                         // Check if removing this piece does not close a mill
+			b.SetPosition(location, Empty)                                              // Remove the opponent piece from the specified location
+			if !CloseMill(location, b, opponent) {                                      // Check if removing this piece does not close a mill
 				*L = append(*L, b) // If it doesn't close a mill, add the resulting board state to the list L
+				added++
 			}
 		}
 	}
 
-	// If no states were added (all black pieces are in mills), add all possible removal states.
+	// If no states were added (all opponent pieces are in mills), add all possible removal states.
 	// This is because if all pieces are mills then you still remove one
-	if len(*L) == 0 {
+	if added == 0 {
 		for location := 0; location < 21; location++ {
-			if board.GetPosition(location) == Black { // Check if the position contains a black piece
+			if board.GetPosition(location) == opponent { // Check if the position contains an opponent piece
 				b := &MorrisBoard{firstHalf: board.firstHalf, secondHalf: board.secondHalf} // Create a copy of the board
-				b.SetPosition(location, Empty)                                              // Remove the black piece from the specified location
+				b.SetPosition(location, Empty)                                              // Remove the opponent piece from the specified location
 				*L = append(*L, b)                                                          // Add the resulting board state to the list L
+				added++
 			}
 		}
 	}
+
+	// If the opponent has no pieces at all, the move stands without a removal
+	if added == 0 {
+		*L = append(*L, board)
+	}
 }
 
 func GenerateAdd(board *MorrisBoard, color int) []*MorrisBoard {
@@ -179,7 +226,7 @@ func GenerateAdd(board *MorrisBoard, color int) []*MorrisBoard {
 			var close = CloseMill(location, b, color)
 			b.SetPosition(location, color) // Place the color token at the specified location
 			if close {
-				GenerateRemove(b, &L) // Generate removal states if placing this piece closes a mill
+				GenerateRemove(b, color, &L) // Generate removal states if placing this piece closes a mill
 			} else {
 				L = append(L, b) // Otherwise, add the board state to the list L
 			}
@@ -273,15 +320,15 @@ func GenerateMove(board *MorrisBoard, color int) []*MorrisBoard {
 				if board.GetPosition(j) == Empty { // Check if the neighbor position is empty
 					// Create a copy of the board
 					b := &MorrisBoard{firstHalf: board.firstHalf, secondHalf: board.secondHalf}
-					var close = CloseMill(j, b, color)
 
-					// Move the white piece from 'location' to 'j'
+					// Move the piece from 'location' to 'j', vacating 'location' first so it cannot count towards a mill at 'j'
 					b.SetPosition(location, Empty)
+					var close = CloseMill(j, b, color)
 					b.SetPosition(j, color)
 
 					// Check if moving to position 'j' forms a mill
 					if close {
-						GenerateRemove(b, &L) // Generate removal states if a mill is formed
+						GenerateRemove(b, color, &L) // Generate removal states if a mill is formed
 					} else {
 						L = append(L, b) // Otherwise, add the resulting board state to the list L
 					}
@@ -304,14 +351,14 @@ func GenerateHopping(board *MorrisBoard, color int) []*MorrisBoard {
 				if board.GetPosition(beta) == Empty { // Check if the position β is empty
 					// Create a copy of the board
 					b := &MorrisBoard{firstHalf: board.firstHalf, secondHalf: board.secondHalf}
-					var close = CloseMill(beta, b, color)
-					// Move the white piece from α to β
+					// Move the piece from α to β, vacating α first so it cannot count towards a mill at β
 					b.SetPosition(alpha, Empty)
+					var close = CloseMill(beta, b, color)
 					b.SetPosition(beta, color)
 
 					// Check if moving to position β forms a mill
 					if close {
-						GenerateRemove(b, &L) // Generate removal states if a mill is formed
+						GenerateRemove(b, color, &L) // Generate removal states if a mill is formed
 					} else {
 						L = append(L, b) // Otherwise, add the resulting board state to the list L
 					}
@@ -324,17 +371,17 @@ func GenerateHopping(board *MorrisBoard, color int) []*MorrisBoard {
 }
 
 func GenerateMovesMidgameEndgame(board *MorrisBoard, color int) []*MorrisBoard {
-	numWhitePieces := 0
+	numPieces := 0
 
-	// Count the number of white pieces on the board
+	// Count the number of pieces of the moving color on the board
 	for location := 0; location < 21; location++ {
-		if board.GetPosition(location) == White {
-			numWhitePieces++
+		if board.GetPosition(location) == color {
+			numPieces++
 		}
 	}
 
-	if numWhitePieces == 3 {
-		return GenerateHopping(board, color) // Generate hopping moves if there are exactly 3 white pieces
+	if numPieces == 3 {
+		return GenerateHopping(board, color) // Generate hopping moves if there are exactly 3 pieces of the moving color
 	}
 	return GenerateMove(board, color) // Generate regular moves otherwise
 
@@ -0,0 +1,61 @@
+package representation
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+// Test that String and ParseBoard round-trip every state at every position, and random boards
+func TestParseBoardRoundTrip(t *testing.T) {
+	var boards []MorrisBoard
+	for position := 0; position < 21; position++ {
+		for state := Empty; state <= Unused; state++ {
+			var b MorrisBoard
+			b.SetPosition(position, state)
+			boards = append(boards, b)
+		}
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		boards = append(boards, MorrisBoard{firstHalf: rng.Uint32(), secondHalf: rng.Uint32() & (1<<10 - 1)})
+	}
+
+	for _, b := range boards {
+		parsed, err := ParseBoard(b.String())
+		if err != nil {
+			t.Fatalf("ParseBoard(%s): %v", b.String(), err)
+		}
+		if parsed != b {
+			t.Fatalf("ParseBoard(%s) = %s", b.String(), parsed.String())
+		}
+	}
+}
+
+func TestParseBoardWhitespace(t *testing.T) {
+	for _, s := range []string{"xxxxxxWxxBxxxxxxBxWxx\n", "xxxxxxWxxBxxxxxxBxWxx\r\n", "  xxxxxxWxxBxxxxxxBxWxx\t"} {
+		b, err := ParseBoard(s)
+		if err != nil || b.String() != "xxxxxxWxxBxxxxxxBxWxx" {
+			t.Errorf("ParseBoard(%q) = %s, %v", s, b.String(), err)
+		}
+	}
+}
+
+func TestParseBoardErrors(t *testing.T) {
+	_, err := ParseBoard("xxxxW\n")
+	var lengthErr *BoardLengthError
+	if !errors.As(err, &lengthErr) || lengthErr.Length != 5 {
+		t.Errorf("short board: got %v, want a BoardLengthError of length 5", err)
+	}
+
+	_, err = ParseBoard("xxxxxxWxxBxxxoxxBxWxx")
+	var charErr *BoardCharError
+	if !errors.As(err, &charErr) || charErr.Char != 'o' || charErr.Index != 13 {
+		t.Errorf("bad character: got %v, want a BoardCharError for 'o' at 13", err)
+	}
+
+	_, err = ParseBoard("xxxx xxWxxBxxxxxxBxWxx")
+	if !errors.As(err, &lengthErr) || lengthErr.Length != 22 {
+		t.Errorf("inner space: got %v, want a BoardLengthError of length 22", err)
+	}
+}
@@ -0,0 +1,200 @@
+package representation
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+)
+
+// --- Neural network evaluator
+//
+// Network is a multilayer perceptron with one hidden layer of rectified linear units. Its inputs are a
+// one-hot encoding of the position: for every square whether White and whether Black stands on it, whether
+// White is to move, and the pieces each side has in hand, as a fraction of PiecesPerSide. Its output v is
+// the expected result for White through the logistic function, 1 / (1 + exp(-v)), and it evaluates to
+// v * NetworkScale so that its estimates compare with those of the other evaluators.
+
+// NetworkInputs is the number of inputs of a Network
+const NetworkInputs = 2*21 + 1 + 2
+
+// NetworkScale converts the output of a network into an estimate
+const NetworkScale = 1000
+
+// networkMagic starts every network file, followed by the format version
+const networkMagic = "MLP1"
+
+// Network is a multilayer perceptron with NetworkInputs inputs, one hidden layer and one output
+type Network struct {
+	Hidden int
+	W1     []float32 // Hidden rows of NetworkInputs weights
+	B1     []float32 // Hidden biases
+	W2     []float32 // Hidden output weights
+	B2     float32   // Output bias
+}
+
+// NewNetwork returns a network with the given number of hidden units and small random weights
+func NewNetwork(hidden int, rng *rand.Rand) *Network {
+	n := &Network{Hidden: hidden, W1: make([]float32, hidden*NetworkInputs), B1: make([]float32, hidden), W2: make([]float32, hidden)}
+	scale1, scale2 := math.Sqrt(2/float64(NetworkInputs)), math.Sqrt(1/float64(hidden))
+	for i := range n.W1 {
+		n.W1[i] = float32(rng.NormFloat64() * scale1)
+	}
+	for i := range n.W2 {
+		n.W2[i] = float32(rng.NormFloat64() * scale2 * 0.1)
+	}
+	return n
+}
+
+// NetworkFeatures encodes a position as the inputs of a network
+func NetworkFeatures(board *MorrisBoard, sideToMove int, whiteInHand int, blackInHand int) [NetworkInputs]float32 {
+	var x [NetworkInputs]float32
+	for position := 0; position < 21; position++ {
+		switch board.GetPosition(position) {
+		case White:
+			x[2*position] = 1
+		case Black:
+			x[2*position+1] = 1
+		}
+	}
+	if sideToMove == White {
+		x[42] = 1
+	}
+	x[43] = float32(whiteInHand) / PiecesPerSide
+	x[44] = float32(blackInHand) / PiecesPerSide
+	return x
+}
+
+// Forward returns the output of the network for the inputs, and the activations of the hidden layer if
+// hidden is not nil
+func (n *Network) Forward(x *[NetworkInputs]float32, hidden []float32) float32 {
+	out := n.B2
+	for h := 0; h < n.Hidden; h++ {
+		sum := n.B1[h]
+		row := n.W1[h*NetworkInputs : (h+1)*NetworkInputs]
+		for i, xi := range x {
+			if xi != 0 {
+				sum += row[i] * xi
+			}
+		}
+		if sum < 0 {
+			sum = 0
+		}
+		if hidden != nil {
+			hidden[h] = sum
+		}
+		out += n.W2[h] * sum
+	}
+	return out
+}
+
+// AddGradient adds to grad, a network of the same shape, the gradient of the cross-entropy loss between
+// the network's expected result for the inputs and target, and returns the loss
+func (n *Network) AddGradient(x *[NetworkInputs]float32, target float32, grad *Network) float32 {
+	hidden := make([]float32, n.Hidden)
+	out := n.Forward(x, hidden)
+	p := float32(1 / (1 + math.Exp(-float64(out))))
+
+	dOut := p - target // Derivative of the cross-entropy through the logistic function
+	grad.B2 += dOut
+	for h := 0; h < n.Hidden; h++ {
+		grad.W2[h] += dOut * hidden[h]
+		if hidden[h] <= 0 {
+			continue
+		}
+		dHidden := dOut * n.W2[h]
+		grad.B1[h] += dHidden
+		row := grad.W1[h*NetworkInputs : (h+1)*NetworkInputs]
+		for i, xi := range x {
+			if xi != 0 {
+				row[i] += dHidden * xi
+			}
+		}
+	}
+
+	const epsilon = 1e-7
+	return -target*float32(math.Log(float64(p)+epsilon)) - (1-target)*float32(math.Log(float64(1-p)+epsilon))
+}
+
+// EvaluatePosition implements PositionEvaluator
+func (n *Network) EvaluatePosition(p Position) int {
+	x := NetworkFeatures(&p.Board, p.SideToMove, p.WhiteInHand, p.BlackInHand)
+	return int(math.Round(float64(n.Forward(&x, nil)) * NetworkScale))
+}
+
+// Evaluate implements Evaluator. A bare board holds no pieces in hand, so it is completed with
+// LegacyPosition, which takes every piece not on the board in the opening to be still in hand.
+func (n *Network) Evaluate(board *MorrisBoard, phase int, sideToMove int) int {
+	return n.EvaluatePosition(LegacyPosition(*board, sideToMove, phase))
+}
+
+// WriteNetwork writes the network in the compact network file format: the magic "MLP1", the number of
+// inputs and of hidden units as little-endian uint16, then every weight as a little-endian float32,
+// W1 row by row, B1, W2 and B2
+func WriteNetwork(w io.Writer, n *Network) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(networkMagic)
+	binary.Write(bw, binary.LittleEndian, [2]uint16{NetworkInputs, uint16(n.Hidden)})
+	for _, weights := range [][]float32{n.W1, n.B1, n.W2, {n.B2}} {
+		if err := binary.Write(bw, binary.LittleEndian, weights); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadNetwork reads a network written by WriteNetwork
+func ReadNetwork(r io.Reader) (*Network, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(networkMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != networkMagic {
+		return nil, fmt.Errorf("not a network file")
+	}
+	var shape [2]uint16
+	if err := binary.Read(br, binary.LittleEndian, &shape); err != nil {
+		return nil, fmt.Errorf("network file: %v", err)
+	}
+	if shape[0] != NetworkInputs || shape[1] == 0 {
+		return nil, fmt.Errorf("network file has %d inputs and %d hidden units, want %d inputs", shape[0], shape[1], NetworkInputs)
+	}
+
+	hidden := int(shape[1])
+	n := &Network{Hidden: hidden, W1: make([]float32, hidden*NetworkInputs), B1: make([]float32, hidden), W2: make([]float32, hidden)}
+	var b2 [1]float32
+	for _, weights := range [][]float32{n.W1, n.B1, n.W2, b2[:]} {
+		if err := binary.Read(br, binary.LittleEndian, weights); err != nil {
+			return nil, fmt.Errorf("network file: %v", err)
+		}
+	}
+	n.B2 = b2[0]
+	return n, nil
+}
+
+// IsNetworkFile reports whether the file at path starts like a network file
+func IsNetworkFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	magic := make([]byte, len(networkMagic))
+	_, err = io.ReadFull(f, magic)
+	return err == nil && string(magic) == networkMagic
+}
+
+// LoadNetwork reads the network file at path
+func LoadNetwork(path string) (*Network, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	n, err := ReadNetwork(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,99 @@
+package representation
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test the gradient of the loss against finite differences
+func TestNetworkGradient(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	n := NewNetwork(8, rng)
+	for i := range n.W2 {
+		n.W2[i] = float32(rng.NormFloat64()) // Larger output weights, so that the gradient is not tiny
+	}
+	board := MorrisBoardFromString("xBxBWxxxxBxBWWxWWWBBx")
+	x := NetworkFeatures(board, Black, 2, 1)
+	target := float32(0.8)
+
+	grad := &Network{Hidden: n.Hidden, W1: make([]float32, len(n.W1)), B1: make([]float32, len(n.B1)), W2: make([]float32, len(n.W2))}
+	n.AddGradient(&x, target, grad)
+
+	check := func(name string, w *float32, analytic float32) {
+		const h = 1e-3
+		original := *w
+		*w = original + h
+		plus := n.AddGradient(&x, target, &Network{Hidden: n.Hidden, W1: make([]float32, len(n.W1)), B1: make([]float32, len(n.B1)), W2: make([]float32, len(n.W2))})
+		*w = original - h
+		minus := n.AddGradient(&x, target, &Network{Hidden: n.Hidden, W1: make([]float32, len(n.W1)), B1: make([]float32, len(n.B1)), W2: make([]float32, len(n.W2))})
+		*w = original
+		numeric := (plus - minus) / (2 * h)
+		if math.Abs(float64(numeric-analytic)) > 1e-2*math.Max(1, math.Abs(float64(numeric))) {
+			t.Errorf("%s: gradient %g, finite difference %g", name, analytic, numeric)
+		}
+	}
+	check("B2", &n.B2, grad.B2)
+	for h := 0; h < n.Hidden; h++ {
+		check("W2", &n.W2[h], grad.W2[h])
+		check("B1", &n.B1[h], grad.B1[h])
+		for _, i := range []int{8, 42, 43} { // White on c2, White to move, White in hand
+			check("W1", &n.W1[h*NetworkInputs+i], grad.W1[h*NetworkInputs+i])
+		}
+	}
+}
+
+// Test that a network reads back from its file unchanged and that other files are refused
+func TestNetworkFile(t *testing.T) {
+	n := NewNetwork(5, rand.New(rand.NewSource(2)))
+	n.B2 = 0.25
+	var buf bytes.Buffer
+	if err := WriteNetwork(&buf, n); err != nil {
+		t.Fatal(err)
+	}
+	if want := 4 + 4 + 4*(5*NetworkInputs+5+5+1); buf.Len() != want {
+		t.Errorf("network file of %d bytes, want %d", buf.Len(), want)
+	}
+	read, err := ReadNetwork(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := mustParsePosition(t, "xBxBWxxxxBxBWWxWWWBBx W 1 0 4 12")
+	if read.EvaluatePosition(p) != n.EvaluatePosition(p) || read.B2 != n.B2 {
+		t.Errorf("network changed on the way through its file")
+	}
+
+	for _, data := range [][]byte{[]byte("evaluator midgame\n"), buf.Bytes()[:20], append([]byte("MLP1"), 3, 0, 5, 0)} {
+		if _, err := ReadNetwork(bytes.NewReader(data)); err == nil {
+			t.Errorf("ReadNetwork accepted %q", data)
+		}
+	}
+}
+
+// Test that --weights with a network file selects the network, and that a bare board is completed with
+// the pieces in hand of LegacyPosition
+func TestNetworkEvaluator(t *testing.T) {
+	n := NewNetwork(4, rand.New(rand.NewSource(3)))
+	path := filepath.Join(t.TempDir(), "net.mlp")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteNetwork(f, n); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	e, _, err := ParseEvaluatorFlag([]string{"--weights", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := MorrisBoardFromString("xxxxxxWxxBxxxxxxBxWxx")
+	want := n.EvaluatePosition(LegacyPosition(*board, White, Opening))
+	if got := e.Evaluate(board, Opening, White); got != want {
+		t.Errorf("network from file evaluates to %d, want %d", got, want)
+	}
+}
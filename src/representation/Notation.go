@@ -0,0 +1,180 @@
+package representation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- Square and move notation
+
+// SquareNames holds the name of every board position, column letter then row number, as in the mills table of CloseMill
+var SquareNames = [21]string{
+	"a0", "g0", "b1", "f1", "c2", "e2",
+	"a3", "b3", "c3", "e3", "f3", "g3",
+	"c4", "d4", "e4", "b5", "d5", "f5",
+	"a6", "d6", "g6",
+}
+
+// NoSquare marks an unused square of a Move
+const NoSquare = -1
+
+// SquareName returns the name of a board position, such as "a0" for position 0
+func SquareName(position int) string {
+	if position < 0 || position >= len(SquareNames) {
+		return "??"
+	}
+	return SquareNames[position]
+}
+
+// ParseSquare returns the board position of a square name, such as 20 for "g6"
+func ParseSquare(s string) (int, error) {
+	for position, name := range SquareNames {
+		if name == s {
+			return position, nil
+		}
+	}
+	return NoSquare, fmt.Errorf("unknown square %q", s)
+}
+
+// Move describes a placement (From is NoSquare), a slide or a hop, and the opponent piece it removes
+// after closing a mill (Remove is NoSquare if none)
+type Move struct {
+	From   int
+	To     int
+	Remove int
+}
+
+// String returns the move in square notation: "a0" places on a0, "b3-b5" moves from b3 to b5, and a trailing
+// "xg6" removes the opponent piece on g6, as in "d4xg6" or "b3-b5xg6"
+func (m Move) String() string {
+	if m.To == NoSquare {
+		return "none"
+	}
+
+	result := SquareName(m.To)
+	if m.From != NoSquare {
+		result = SquareName(m.From) + "-" + result
+	}
+	if m.Remove != NoSquare {
+		result += "x" + SquareName(m.Remove)
+	}
+	return result
+}
+
+// ParseMove reads a move in the square notation produced by Move.String
+func ParseMove(s string) (Move, error) {
+	m := Move{From: NoSquare, To: NoSquare, Remove: NoSquare}
+	rest := s
+
+	if before, after, found := strings.Cut(rest, "x"); found {
+		remove, err := ParseSquare(after)
+		if err != nil {
+			return m, fmt.Errorf("invalid move %q: %v", s, err)
+		}
+		m.Remove, rest = remove, before
+	}
+
+	if before, after, found := strings.Cut(rest, "-"); found {
+		from, err := ParseSquare(before)
+		if err != nil {
+			return m, fmt.Errorf("invalid move %q: %v", s, err)
+		}
+		m.From, rest = from, after
+	}
+
+	to, err := ParseSquare(rest)
+	if err != nil {
+		return m, fmt.Errorf("invalid move %q: %v", s, err)
+	}
+	m.To = to
+	return m, nil
+}
+
+// Apply returns the board after color plays m, without checking that m is legal
+func (b *MorrisBoard) Apply(m Move, color int) *MorrisBoard {
+	next := &MorrisBoard{firstHalf: b.firstHalf, secondHalf: b.secondHalf}
+	if m.From != NoSquare {
+		next.SetPosition(m.From, Empty)
+	}
+	next.SetPosition(m.To, color)
+	if m.Remove != NoSquare {
+		next.SetPosition(m.Remove, Empty)
+	}
+	return next
+}
+
+// MoveBetween returns the move of color that turns before into after, where after is one of the
+// board states generated from before. It returns a move with To set to NoSquare if there is none.
+func MoveBetween(before *MorrisBoard, after *MorrisBoard, color int) Move {
+	m := Move{From: NoSquare, To: NoSquare, Remove: NoSquare}
+	for position := 0; position < 21; position++ {
+		was, is := before.GetPosition(position), after.GetPosition(position)
+		switch {
+		case was == Empty && is == color:
+			m.To = position
+		case was == color && is == Empty:
+			m.From = position
+		case was == 3-color && is == Empty:
+			m.Remove = position
+		}
+	}
+
+	if m.To == NoSquare || *before.Apply(m, color) != *after {
+		return Move{From: NoSquare, To: NoSquare, Remove: NoSquare}
+	}
+	return m
+}
+
+// LegalMoves returns the moves color can play in the given phase, in move generator order
+func LegalMoves(board *MorrisBoard, color int, phase int) []Move {
+	boards := GenerateMoves(board, color, phase)
+	moves := make([]Move, 0, len(boards))
+	for _, next := range boards {
+		moves = append(moves, MoveBetween(board, next, color))
+	}
+	return moves
+}
+
+// PlayMove returns the board after color plays m, or an error if m is not legal in the given phase
+func PlayMove(board *MorrisBoard, m Move, color int, phase int) (*MorrisBoard, error) {
+	for _, legal := range LegalMoves(board, color, phase) {
+		if legal == m {
+			return board.Apply(m, color), nil
+		}
+	}
+	return nil, fmt.Errorf("illegal move %s on %s", m.String(), board.String())
+}
+
+// ParseBoardAndMoves reads a board string optionally followed by moves in square notation, such as
+// "xxxxxxWxxBxxxxxxBxWxx d4 a0xb3". The moves are played alternately in the given phase, the last one
+// by the opponent of color, and the board reached with color to move is returned with them.
+func ParseBoardAndMoves(s string, color int, phase int) (*MorrisBoard, []Move, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, nil, &BoardLengthError{Length: 0}
+	}
+
+	parsed, err := ParseBoard(fields[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	board := &parsed
+
+	moves := make([]Move, 0, len(fields)-1)
+	mover := color
+	if len(fields)%2 == 0 {
+		mover = 3 - color // An odd number of moves starts with the opponent
+	}
+	for _, field := range fields[1:] {
+		m, err := ParseMove(field)
+		if err != nil {
+			return nil, nil, err
+		}
+		if board, err = PlayMove(board, m, mover, phase); err != nil {
+			return nil, nil, err
+		}
+		moves, mover = append(moves, m), 3-mover
+	}
+
+	return board, moves, nil
+}
@@ -0,0 +1,73 @@
+package representation
+
+import (
+	"testing"
+)
+
+func TestSquareNames(t *testing.T) {
+	for position := 0; position < 21; position++ {
+		got, err := ParseSquare(SquareName(position))
+		if err != nil || got != position {
+			t.Errorf("ParseSquare(%s) = %d, %v, want %d", SquareName(position), got, err, position)
+		}
+	}
+	if _, err := ParseSquare("d0"); err == nil {
+		t.Errorf("ParseSquare(d0) accepted a square that is not on the board")
+	}
+}
+
+func TestParseMove(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Move
+	}{
+		{"a0", Move{From: NoSquare, To: 0, Remove: NoSquare}},
+		{"b3-b5", Move{From: 7, To: 15, Remove: NoSquare}},
+		{"d4xg6", Move{From: NoSquare, To: 13, Remove: 20}},
+		{"a3-a0xf5", Move{From: 6, To: 0, Remove: 17}},
+	}
+	for _, tc := range tests {
+		got, err := ParseMove(tc.s)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseMove(%s) = %+v, %v, want %+v", tc.s, got, err, tc.want)
+		}
+		if got.String() != tc.s {
+			t.Errorf("ParseMove(%s).String() = %s", tc.s, got.String())
+		}
+	}
+
+	for _, s := range []string{"", "a0-", "h1", "b3-b5xx", "a0xg6-b1"} {
+		if m, err := ParseMove(s); err == nil {
+			t.Errorf("ParseMove(%q) = %+v, want an error", s, m)
+		}
+	}
+}
+
+// Test that every generated board state is described by a move that recreates it
+func TestLegalMovesMatchGenerators(t *testing.T) {
+	for _, tc := range perftTable {
+		board := MorrisBoardFromString(tc.board)
+		boards := GenerateMoves(board, tc.color, tc.phase)
+		moves := LegalMoves(board, tc.color, tc.phase)
+		for i, m := range moves {
+			if m.To == NoSquare || *board.Apply(m, tc.color) != *boards[i] {
+				t.Errorf("%s: move %s does not lead to %s", tc.board, m.String(), boards[i].String())
+			}
+		}
+	}
+}
+
+func TestParseBoardAndMoves(t *testing.T) {
+	// Black places on d4, White closes the a-file mill and removes it
+	board, moves, err := ParseBoardAndMoves("xxxxxxWxxBxxxxxxBxWxx\n d4 a0xd4", Black, Opening)
+	if err != nil {
+		t.Fatalf("ParseBoardAndMoves: %v", err)
+	}
+	if len(moves) != 2 || board.String() != "WxxxxxWxxBxxxxxxBxWxx" {
+		t.Errorf("ParseBoardAndMoves = %s after %v", board.String(), moves)
+	}
+
+	if _, _, err := ParseBoardAndMoves("xxxxxxWxxBxxxxxxBxWxx a3", Black, Opening); err == nil {
+		t.Errorf("ParseBoardAndMoves accepted a placement on an occupied square")
+	}
+}
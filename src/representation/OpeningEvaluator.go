@@ -0,0 +1,145 @@
+package representation
+
+// --- Opening evaluator
+//
+// StaticEstimateOpeningNaive only counts pieces, so shallow opening searches cannot tell most placements
+// apart. OpeningEvaluator scores the shape of the position as well. Every term is counted for White and
+// for Black and contributes its weight times the difference.
+
+// OpeningWeights are the weights of the terms of OpeningEvaluator. A negative weight makes a term a penalty.
+type OpeningWeights struct {
+	Material      int // Per piece on the board
+	Mills         int // Per closed mill
+	TwoInARows    int // Per mill with two own pieces and an empty square, a potential mill
+	DoubleThreats int // Per empty square closing a mill beyond the first, the opponent can only block one
+	Blocked       int // Per piece without an empty neighbor
+	Mobility      int // Per empty neighbor of an own piece
+	Junctions     int // Per piece on a junction, a square with 4 neighbors
+}
+
+// DefaultOpeningWeights are the weights of the "opening" evaluator, on the scale of StaticEstimateMidgameEndgame
+// so that search trees crossing into the midgame compare like with like
+var DefaultOpeningWeights = OpeningWeights{
+	Material:      1000,
+	Mills:         200,
+	TwoInARows:    150,
+	DoubleThreats: 300,
+	Blocked:       -60,
+	Mobility:      20,
+	Junctions:     40,
+}
+
+// OpeningEvaluator evaluates placement phase positions by the terms of OpeningWeights. In the midgame it
+// defers to StaticEstimateMidgameEndgame.
+type OpeningEvaluator struct {
+	Weights OpeningWeights
+}
+
+func init() {
+	RegisterEvaluator("opening", &OpeningEvaluator{Weights: DefaultOpeningWeights})
+}
+
+// millLines holds every mill once, as opposed to mills, which lists each once per position
+var millLines = uniqueMills()
+
+// MillLines returns the squares of every mill, each mill once
+func MillLines() [][3]int {
+	return append([][3]int(nil), millLines...)
+}
+
+func uniqueMills() [][3]int {
+	seen := map[[3]int]bool{}
+	var lines [][3]int
+	for _, mill := range mills {
+		line := sortedTriple(mill[0], mill[1], mill[2])
+		if !seen[line] {
+			seen[line] = true
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// OpeningTerms counts the terms of OpeningWeights for one color, in the order of the struct fields
+type OpeningTerms struct {
+	Material, Mills, TwoInARows, DoubleThreats, Blocked, Mobility, Junctions int
+}
+
+// CountOpeningTerms counts the terms of OpeningWeights for color
+func CountOpeningTerms(board *MorrisBoard, color int) OpeningTerms {
+	var terms OpeningTerms
+
+	closing := map[int]bool{} // Empty squares that close a mill for color
+	for _, line := range millLines {
+		own, empty, emptyAt := 0, 0, NoSquare
+		for _, position := range line {
+			switch board.GetPosition(position) {
+			case color:
+				own++
+			case Empty:
+				empty, emptyAt = empty+1, position
+			}
+		}
+		switch {
+		case own == 3:
+			terms.Mills++
+		case own == 2 && empty == 1:
+			terms.TwoInARows++
+			closing[emptyAt] = true
+		}
+	}
+	terms.DoubleThreats = max(len(closing)-1, 0)
+
+	for position := 0; position < 21; position++ {
+		if board.GetPosition(position) != color {
+			continue
+		}
+		terms.Material++
+		free := 0
+		for _, n := range Neighbors(position) {
+			if board.GetPosition(n) == Empty {
+				free++
+			}
+		}
+		terms.Mobility += free
+		if free == 0 {
+			terms.Blocked++
+		}
+		if len(Neighbors(position)) == 4 {
+			terms.Junctions++
+		}
+	}
+	return terms
+}
+
+// Score returns the weighted sum of the terms
+func (w OpeningWeights) Score(t OpeningTerms) int {
+	return w.Material*t.Material + w.Mills*t.Mills + w.TwoInARows*t.TwoInARows + w.DoubleThreats*t.DoubleThreats +
+		w.Blocked*t.Blocked + w.Mobility*t.Mobility + w.Junctions*t.Junctions
+}
+
+// Evaluate implements Evaluator
+func (e *OpeningEvaluator) Evaluate(board *MorrisBoard, phase int, sideToMove int) int {
+	if phase != Opening {
+		return StaticEstimateMidgameEndgame(board)
+	}
+	return e.Weights.Score(CountOpeningTerms(board, White)) - e.Weights.Score(CountOpeningTerms(board, Black))
+}
+
+// Explain implements Explainer, one term per weight with the White minus Black difference as its value
+func (e *OpeningEvaluator) Explain(board *MorrisBoard, phase int, sideToMove int) Explanation {
+	if phase != Opening {
+		return explainMidgameEndgame(board)
+	}
+	white, black := CountOpeningTerms(board, White), CountOpeningTerms(board, Black)
+	w := e.Weights
+	var x Explanation
+	x.add("material", white.Material-black.Material, w.Material)
+	x.add("mills", white.Mills-black.Mills, w.Mills)
+	x.add("two in a rows", white.TwoInARows-black.TwoInARows, w.TwoInARows)
+	x.add("double threats", white.DoubleThreats-black.DoubleThreats, w.DoubleThreats)
+	x.add("blocked pieces", white.Blocked-black.Blocked, w.Blocked)
+	x.add("mobility", white.Mobility-black.Mobility, w.Mobility)
+	x.add("junctions", white.Junctions-black.Junctions, w.Junctions)
+	return x
+}
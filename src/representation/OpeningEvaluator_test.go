@@ -0,0 +1,76 @@
+package representation
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Test the terms counted for each color on a hand-checked board
+func TestCountOpeningTerms(t *testing.T) {
+	// White: mill a0-b1-c2 (0, 2, 4) and five two in a rows, closed on a6, c3 (twice), b5 and e4; b1 is blocked in
+	// Black: f1 (3) and e2 (5), one free neighbor each
+	b := mustParseBoard(t, "WxWBWBWWxxxxWWxxxxxxx")
+	board := &b
+
+	white := CountOpeningTerms(board, White)
+	want := OpeningTerms{Material: 7, Mills: 1, TwoInARows: 5, DoubleThreats: 3, Blocked: 1, Mobility: 9, Junctions: 2}
+	if white != want {
+		t.Errorf("White terms = %+v, want %+v", white, want)
+	}
+
+	black := CountOpeningTerms(board, Black)
+	want = OpeningTerms{Material: 2, Mills: 0, TwoInARows: 0, DoubleThreats: 0, Blocked: 0, Mobility: 2, Junctions: 0}
+	if black != want {
+		t.Errorf("Black terms = %+v, want %+v", black, want)
+	}
+}
+
+// Test that the evaluator is the weighted difference of the terms, and that an empty board is balanced
+func TestOpeningEvaluator(t *testing.T) {
+	e := &OpeningEvaluator{Weights: OpeningWeights{Material: 1, Mills: 2, TwoInARows: 3, DoubleThreats: 4, Blocked: 5, Mobility: 6, Junctions: 7}}
+	b := mustParseBoard(t, "WxWBWBWWxxxxWWxxxxxxx")
+	board := &b
+	want := (7 + 2 + 3*5 + 4*3 + 5 + 6*9 + 7*2) - (2 + 6*2)
+	if got := e.Evaluate(board, Opening, Black); got != want {
+		t.Errorf("Evaluate = %d, want %d", got, want)
+	}
+	if got, want := e.Evaluate(board, MidgameEndgame, Black), StaticEstimateMidgameEndgame(board); got != want {
+		t.Errorf("Evaluate in the midgame = %d, want StaticEstimateMidgameEndgame %d", got, want)
+	}
+	if got := e.Evaluate(&MorrisBoard{}, Opening, White); got != 0 {
+		t.Errorf("Evaluate of the empty board = %d, want 0", got)
+	}
+}
+
+// Test that the opening evaluator beats the standard one at equal depth, with both colors from every opening
+func TestOpeningEvaluatorBeatsStandard(t *testing.T) {
+	openings := 10
+	if testing.Short() {
+		openings = 4
+	}
+	for _, depth := range []int{1, 2} {
+		opening, _ := NewEngine("opening", depth)
+		standard, _ := NewEngine("standard", depth)
+		r := PlayMatch(opening, standard, OpeningSuite(rand.New(rand.NewSource(1)), openings, 2), 300)
+		t.Logf("depth %d: opening vs standard +%d =%d -%d, score %.2f", depth, r.Wins, r.Draws, r.Losses, r.Score())
+		if r.Wins <= r.Losses || r.Score() < 0.6 {
+			t.Errorf("depth %d: opening evaluator scored only +%d =%d -%d against the standard one", depth, r.Wins, r.Draws, r.Losses)
+		}
+	}
+}
+
+// Test that every mill is listed once and that each closes on all of its squares
+func TestMillLines(t *testing.T) {
+	lines := MillLines()
+	if len(lines) != 13 {
+		t.Errorf("%d mills, want 13", len(lines))
+	}
+	for _, line := range lines {
+		var board MorrisBoard
+		board.SetPosition(line[1], White)
+		board.SetPosition(line[2], White)
+		if !CloseMill(line[0], &board, White) {
+			t.Errorf("mill %v does not close on %s", line, SquareName(line[0]))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package representation
+
+// --- Perft (move generator verification)
+
+// Constants representing the phase of the game, which selects the move generator
+const (
+	Opening        = 0 // Pieces are placed with GenerateAdd
+	MidgameEndgame = 1 // Pieces slide with GenerateMove, or hop with GenerateHopping
+)
+
+// GenerateMoves returns the board states reachable by color in one move of the given phase
+func GenerateMoves(board *MorrisBoard, color int, phase int) []*MorrisBoard {
+	if phase == Opening {
+		return GenerateAdd(board, color)
+	}
+	return GenerateMovesMidgameEndgame(board, color)
+}
+
+// Perft counts the leaf positions of the game tree of the given depth, with color to move at the root.
+// Sides alternate every ply and every ply is played in the same phase.
+func Perft(board *MorrisBoard, color int, depth int, phase int) int {
+	if depth == 0 {
+		return 1
+	}
+
+	moves := GenerateMoves(board, color, phase)
+	if depth == 1 {
+		return len(moves) // Bulk count, the leaves are the moves themselves
+	}
+
+	nodes := 0
+	for _, move := range moves {
+		nodes += Perft(move, 3-color, depth-1, phase)
+	}
+	return nodes
+}
+
+// PerftEntry is the number of leaf positions found below one root move
+type PerftEntry struct {
+	Move  Move         // Root move
+	Board *MorrisBoard // Board state after the root move
+	Nodes int          // Leaf positions below it
+}
+
+// PerftDivide runs Perft below every root move separately, in generator order
+func PerftDivide(board *MorrisBoard, color int, depth int, phase int) []PerftEntry {
+	if depth == 0 {
+		return nil
+	}
+
+	moves := GenerateMoves(board, color, phase)
+	entries := make([]PerftEntry, 0, len(moves))
+	for _, move := range moves {
+		entries = append(entries, PerftEntry{Move: MoveBetween(board, move, color), Board: move, Nodes: Perft(move, 3-color, depth-1, phase)})
+	}
+	return entries
+}
+
+// PerftPosition counts the leaf positions of the game tree of the given depth below p. Unlike Perft it
+// follows the pieces in hand, so the tree crosses from the opening into the midgame.
+func PerftPosition(p Position, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+
+	moves := p.LegalMoves()
+	if depth == 1 {
+		return len(moves) // Bulk count, the leaves are the moves themselves
+	}
+
+	nodes := 0
+	for _, m := range moves {
+		nodes += PerftPosition(p.played(m), depth-1)
+	}
+	return nodes
+}
+
+// PerftPositionDivide runs PerftPosition below every root move separately, in generator order
+func PerftPositionDivide(p Position, depth int) []PerftEntry {
+	if depth == 0 {
+		return nil
+	}
+
+	moves := p.LegalMoves()
+	entries := make([]PerftEntry, 0, len(moves))
+	for _, m := range moves {
+		next := p.played(m)
+		entries = append(entries, PerftEntry{Move: m, Board: &next.Board, Nodes: PerftPosition(next, depth-1)})
+	}
+	return entries
+}
@@ -0,0 +1,76 @@
+package representation
+
+import (
+	"testing"
+)
+
+// Known perft counts, which agree with the rules-first generator in the reference package. Any change
+// to GenerateAdd, GenerateMove, GenerateHopping or GenerateRemove that alters one of these is a bug.
+var perftTable = []struct {
+	board  string
+	color  int
+	phase  int
+	counts []int // counts[d-1] is the perft count at depth d
+}{
+	// Empty board: 21 * 20 * 19 * 18 placements, then the first mills appear at depth 5
+	{"xxxxxxxxxxxxxxxxxxxxx", White, Opening, []int{21, 420, 7980, 143640, 2465748}},
+	{"xxxxxxWxxBxxxxxxBxWxx", White, Opening, []int{18, 290, 4984, 75504}},
+	{"xxxxxxWxxBxxxxxxBxWxx", Black, Opening, []int{17, 304, 4848, 81244}},
+
+	// Midgame fixtures, including Black hopping with three pieces and both sides hopping
+	{"xBxBWxxxxBxBWWxWWWBBB", White, MidgameEndgame, []int{9, 80, 810}},
+	{"xBxBWxxxxBxBWWxWWWBBB", Black, MidgameEndgame, []int{10, 91, 761}},
+	{"xWWBWxxxxWxWWBBBBBBxW", White, MidgameEndgame, []int{13, 128, 1583}},
+	{"xWWBWxxxxWxWWBBBBBBxW", Black, MidgameEndgame, []int{10, 117, 1406}},
+	{"WBxWxWBxxWWWxxWWxWxBx", White, MidgameEndgame, []int{7, 189, 2403}},
+	{"WBxWxWBxxWWWxxWWxWxBx", Black, MidgameEndgame, []int{27, 204, 5695}},
+	{"WxWxxxxxxxxxxxxxBxWBx", White, MidgameEndgame, []int{50, 241, 11802}},
+	{"WxWxxxxxxxxxxxxxBxWBx", Black, MidgameEndgame, []int{5, 250, 1152}},
+}
+
+func TestPerft(t *testing.T) {
+	for _, tc := range perftTable {
+		board := MorrisBoardFromString(tc.board)
+		if board == nil {
+			t.Fatalf("invalid fixture board %s", tc.board)
+		}
+		for i, want := range tc.counts {
+			depth := i + 1
+			if testing.Short() && want > 100000 {
+				continue
+			}
+			if got := Perft(board, tc.color, depth, tc.phase); got != want {
+				t.Errorf("Perft(%s, color %d, depth %d, phase %d) = %d, want %d", tc.board, tc.color, depth, tc.phase, got, want)
+			}
+		}
+	}
+}
+
+// Test that the divide breakdown adds up to the perft count of the parent
+func TestPerftDivide(t *testing.T) {
+	board := MorrisBoardFromString("xBxBWxxxxBxBWWxWWWBBB")
+	entries := PerftDivide(board, White, 3, MidgameEndgame)
+
+	total := 0
+	for _, e := range entries {
+		total += e.Nodes
+	}
+	if len(entries) != 9 || total != 810 {
+		t.Errorf("PerftDivide: %d moves with %d nodes, want 9 moves with 810 nodes", len(entries), total)
+	}
+}
+
+// Test that position perft follows the pieces in hand into the midgame
+func TestPerftPosition(t *testing.T) {
+	for depth, want := range []int{1, 21, 420, 7980, 143640} {
+		if got := PerftPosition(StartPosition(), depth); got != want {
+			t.Errorf("PerftPosition(start, %d) = %d, want %d", depth, got, want)
+		}
+	}
+
+	// One piece each: two placements, then White slides its only piece
+	p, _ := ParsePosition("xxxxxxxxxxxxxxxxxxxxx W 1 1 0 1")
+	if got := PerftPosition(p, 3); got != 1254 {
+		t.Errorf("PerftPosition(%s, 3) = %d, want 1254", p.String(), got)
+	}
+}
@@ -0,0 +1,233 @@
+package representation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// --- Extended position notation
+
+// PiecesPerSide is the number of pieces each player has in hand at the start of the game
+const PiecesPerSide = 9
+
+// Position is a board together with the game state a bare board string cannot hold. Its text form is
+// the board followed by the side to move, the pieces White and Black still have in hand, the halfmove
+// clock and the move number, such as "xxxxxxWxxBxxxxxxBxWxx B 7 7 0 3".
+type Position struct {
+	Board         MorrisBoard
+	SideToMove    int // White or Black
+	WhiteInHand   int // Pieces White has yet to place
+	BlackInHand   int // Pieces Black has yet to place
+	HalfmoveClock int // Moves since the last placement or removal
+	MoveNumber    int // Starts at 1 and is incremented after every Black move
+}
+
+// StartPosition returns the empty board with White to move and all pieces in hand
+func StartPosition() Position {
+	return Position{SideToMove: White, WhiteInHand: PiecesPerSide, BlackInHand: PiecesPerSide, MoveNumber: 1}
+}
+
+// InHand returns the number of pieces color has yet to place
+func (p Position) InHand(color int) int {
+	if color == White {
+		return p.WhiteInHand
+	}
+	return p.BlackInHand
+}
+
+// Phase returns Opening while the side to move still has pieces in hand, MidgameEndgame afterwards
+func (p Position) Phase() int {
+	if p.InHand(p.SideToMove) > 0 {
+		return Opening
+	}
+	return MidgameEndgame
+}
+
+// CheckPhase returns an error unless the position can be searched in phase alone, as the legacy searchers
+// do: in the opening the side to move has pieces in hand, in the midgame neither side has
+func (p Position) CheckPhase(phase int) error {
+	switch {
+	case phase == Opening && p.Phase() != Opening:
+		return fmt.Errorf("position %s is past the opening, %s has no pieces in hand", p, colorName(p.SideToMove))
+	case phase == MidgameEndgame && (p.WhiteInHand > 0 || p.BlackInHand > 0):
+		return fmt.Errorf("position %s is still in the opening, with pieces in hand", p)
+	}
+	return nil
+}
+
+// LegalMoves returns the moves of the side to move, in move generator order
+func (p Position) LegalMoves() []Move {
+	return LegalMoves(&p.Board, p.SideToMove, p.Phase())
+}
+
+// Play returns the position after the side to move plays m, or an error if m is not legal
+func (p Position) Play(m Move) (Position, error) {
+	if _, err := PlayMove(&p.Board, m, p.SideToMove, p.Phase()); err != nil {
+		return p, err
+	}
+	return p.played(m), nil
+}
+
+// played returns the position after the side to move plays m, which must be legal
+func (p Position) played(m Move) Position {
+	next := p
+	next.Board = *p.Board.Apply(m, p.SideToMove)
+	if m.From == NoSquare {
+		if p.SideToMove == White {
+			next.WhiteInHand--
+		} else {
+			next.BlackInHand--
+		}
+	}
+	if m.From == NoSquare || m.Remove != NoSquare {
+		next.HalfmoveClock = 0
+	} else {
+		next.HalfmoveClock++
+	}
+	if p.SideToMove == Black {
+		next.MoveNumber++
+	}
+	next.SideToMove = 3 - p.SideToMove
+	return next
+}
+
+// String returns the position in extended notation
+func (p Position) String() string {
+	return fmt.Sprintf("%s %s %d %d %d %d", p.Board.String(), colorLetter(p.SideToMove),
+		p.WhiteInHand, p.BlackInHand, p.HalfmoveClock, p.MoveNumber)
+}
+
+// Format returns the position in extended notation, or only its board string when extended is false
+func (p Position) Format(extended bool) string {
+	if extended {
+		return p.String()
+	}
+	return p.Board.String()
+}
+
+func colorLetter(color int) string {
+	if color == Black {
+		return "B"
+	}
+	return "W"
+}
+
+// ParsePosition reads a position in extended notation, the inverse of Position.String
+func ParsePosition(s string) (Position, error) {
+	var p Position
+	fields := strings.Fields(s)
+	if len(fields) != 6 {
+		return p, fmt.Errorf("position must have 6 fields (board, side, white in hand, black in hand, halfmove clock, move number), got %d", len(fields))
+	}
+
+	board, err := ParseBoard(fields[0])
+	if err != nil {
+		return p, err
+	}
+	p.Board = board
+
+	switch fields[1] {
+	case "W":
+		p.SideToMove = White
+	case "B":
+		p.SideToMove = Black
+	default:
+		return p, fmt.Errorf("invalid side to move %q, want W or B", fields[1])
+	}
+
+	counters := []struct {
+		name  string
+		value *int
+		min   int
+		max   int
+	}{
+		{"white in hand", &p.WhiteInHand, 0, PiecesPerSide},
+		{"black in hand", &p.BlackInHand, 0, PiecesPerSide},
+		{"halfmove clock", &p.HalfmoveClock, 0, 1 << 30},
+		{"move number", &p.MoveNumber, 1, 1 << 30},
+	}
+	for i, counter := range counters {
+		n, err := strconv.Atoi(fields[i+2])
+		if err != nil || n < counter.min || n > counter.max {
+			return p, fmt.Errorf("invalid %s %q, want a number from %d to %d", counter.name, fields[i+2], counter.min, counter.max)
+		}
+		*counter.value = n
+	}
+	for _, color := range []int{White, Black} {
+		if pieces := p.Pieces(color); pieces > PiecesPerSide {
+			return p, fmt.Errorf("%s has %d pieces on the board and in hand, more than %d", colorName(color), pieces, PiecesPerSide)
+		}
+	}
+
+	return p, nil
+}
+
+// IsExtendedPosition reports whether s holds a position in extended notation rather than a legacy board string
+func IsExtendedPosition(s string) bool {
+	fields := strings.Fields(s)
+	return len(fields) >= 6 && (fields[1] == "W" || fields[1] == "B")
+}
+
+// LegacyPosition completes a legacy board string with color to move. In the opening every piece not on the
+// board is taken to be still in hand, as captures cannot be told apart; in the midgame no pieces are in hand.
+func LegacyPosition(board MorrisBoard, color int, phase int) Position {
+	p := Position{Board: board, SideToMove: color, MoveNumber: 1}
+	if phase == Opening {
+		for position := 0; position < 21; position++ {
+			switch board.GetPosition(position) {
+			case White:
+				p.WhiteInHand++
+			case Black:
+				p.BlackInHand++
+			}
+		}
+		p.WhiteInHand = max(PiecesPerSide-p.WhiteInHand, 0)
+		p.BlackInHand = max(PiecesPerSide-p.BlackInHand, 0)
+	}
+	return p
+}
+
+// ParsePositionAndMoves reads a position in extended notation, or a legacy board string, optionally followed
+// by moves in square notation. Moves after an extended position are played from its side to move. Moves after
+// a legacy board are played as in ParseBoardAndMoves, so that color is to move once they are done, and the
+// legacy board is completed with LegacyPosition for the given phase.
+func ParsePositionAndMoves(s string, color int, phase int) (Position, []Move, error) {
+	if !IsExtendedPosition(s) {
+		fields := strings.Fields(s)
+		if len(fields) == 0 {
+			return Position{}, nil, &BoardLengthError{Length: 0}
+		}
+		board, err := ParseBoard(fields[0])
+		if err != nil {
+			return Position{}, nil, err
+		}
+		first := color
+		if len(fields)%2 == 0 {
+			first = 3 - color // An odd number of moves starts with the opponent
+		}
+		return playMoves(LegacyPosition(board, first, phase), fields[1:])
+	}
+
+	fields := strings.Fields(s)
+	p, err := ParsePosition(strings.Join(fields[:6], " "))
+	if err != nil {
+		return p, nil, err
+	}
+	return playMoves(p, fields[6:])
+}
+
+func playMoves(p Position, fields []string) (Position, []Move, error) {
+	moves := make([]Move, 0, len(fields))
+	for _, field := range fields {
+		m, err := ParseMove(field)
+		if err != nil {
+			return p, nil, err
+		}
+		if p, err = p.Play(m); err != nil {
+			return p, nil, err
+		}
+		moves = append(moves, m)
+	}
+	return p, moves, nil
+}
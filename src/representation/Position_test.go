@@ -0,0 +1,101 @@
+package representation
+
+import (
+	"testing"
+)
+
+func TestPositionRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"xxxxxxxxxxxxxxxxxxxxx W 9 9 0 1",
+		"xxxxxxWxxBxxxxxxBxWxx B 7 7 0 2",
+		"WBxWxWBxxWWWxxWWxWxBx W 0 0 12 31",
+	} {
+		p, err := ParsePosition(s)
+		if err != nil {
+			t.Fatalf("ParsePosition(%s): %v", s, err)
+		}
+		if p.String() != s {
+			t.Errorf("ParsePosition(%s).String() = %s", s, p.String())
+		}
+	}
+
+	for _, s := range []string{
+		"xxxxxxxxxxxxxxxxxxxxx",
+		"xxxxxxxxxxxxxxxxxxxxx w 9 9 0 1",
+		"xxxxxxxxxxxxxxxxxxxxx W 10 9 0 1",
+		"xxxxxxxxxxxxxxxxxxxxx W 9 9 0 0",
+		"xxxxxxxxxxxxxxxxxxxx W 9 9 0 1",
+		"WWWWWWWWWxxxxxxxxxxxB W 9 8 0 1",
+		"WxxxxxxxxxxxxxxxxxxxB B 9 9 0 1",
+	} {
+		if _, err := ParsePosition(s); err == nil {
+			t.Errorf("ParsePosition(%s) accepted an invalid position", s)
+		}
+	}
+}
+
+// Test that playing moves keeps track of pieces in hand, the clocks and the phase
+func TestPositionPlay(t *testing.T) {
+	p := StartPosition()
+	for _, s := range []string{"a0", "g0", "a3", "g3", "a6xg0"} {
+		m, _ := ParseMove(s)
+		next, err := p.Play(m)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		p = next
+	}
+	if got := p.String(); got != "WxxxxxWxxxxBxxxxxxWxx B 6 7 0 3" {
+		t.Errorf("position after a0 g0 a3 g3 a6xg0 = %s", got)
+	}
+
+	p, _ = ParsePosition("WxxxxxWxxxxBxxxxxxWxx W 0 0 4 20")
+	m, _ := ParseMove("a0-b1")
+	if p.Phase() != MidgameEndgame {
+		t.Errorf("Phase() = %d with no pieces in hand", p.Phase())
+	}
+	if p, err := p.Play(m); err != nil || p.String() != "xxWxxxWxxxxBxxxxxxWxx B 0 0 5 20" {
+		t.Errorf("Play(a0-b1) = %s, %v", p.String(), err)
+	}
+}
+
+func TestPositionCheckPhase(t *testing.T) {
+	for _, test := range []struct {
+		position string
+		phase    int
+		ok       bool
+	}{
+		{"xxxxxxxxxxxxxxxxxxxxx W 9 9 0 1", Opening, true},
+		{"xxxxxxxxxxxxxxxxxxxxx W 9 9 0 1", MidgameEndgame, false},
+		{"WWWxBBxxxxxxxxxxxxxxx W 0 0 0 20", Opening, false},
+		{"WWWxBBxxxxxxxxxxxxxxx W 0 0 0 20", MidgameEndgame, true},
+		{"WWWxBBxxxxxxxxxxxxxxx B 0 1 0 9", Opening, true},
+		{"WWWxBBxxxxxxxxxxxxxxx W 0 1 0 10", MidgameEndgame, false},
+	} {
+		p, _ := ParsePosition(test.position)
+		if err := p.CheckPhase(test.phase); (err == nil) != test.ok {
+			t.Errorf("CheckPhase(%s, %d) = %v", test.position, test.phase, err)
+		}
+	}
+}
+
+func TestParsePositionAndMoves(t *testing.T) {
+	// Legacy board: Black, the given color, played first so that it is to move again after White's reply
+	p, moves, err := ParsePositionAndMoves("xxxxxxWxxBxxxxxxBxWxx d4 a0xd4\n", Black, Opening)
+	if err != nil || len(moves) != 2 {
+		t.Fatalf("ParsePositionAndMoves: %v, %v", moves, err)
+	}
+	if got := p.String(); got != "WxxxxxWxxBxxxxxxBxWxx B 6 6 0 2" {
+		t.Errorf("legacy board with moves = %s", got)
+	}
+
+	// Extended position: moves are played from its own side to move
+	p, _, err = ParsePositionAndMoves("xxxxxxWxxBxxxxxxBxWxx B 7 7 0 2 d4", White, Opening)
+	if err != nil || p.String() != "xxxxxxWxxBxxxBxxBxWxx W 7 6 0 3" {
+		t.Errorf("extended position with moves = %s, %v", p.String(), err)
+	}
+
+	if !IsExtendedPosition("xxxxxxxxxxxxxxxxxxxxx W 9 9 0 1") || IsExtendedPosition("xxxxxxxxxxxxxxxxxxxxx a0") {
+		t.Errorf("IsExtendedPosition does not tell extended positions from legacy boards")
+	}
+}
@@ -0,0 +1,184 @@
+package representation
+
+import (
+	"math"
+	"time"
+)
+
+// --- Position search
+//
+// Search plays whole games: unlike the legacy CLIs it follows the pieces in hand from the opening into the
+// midgame, and it knows when a game is over. Estimates are from White's point of view, White maximizing.
+
+// WinEstimate is the estimate of a won position, less the plies it takes to win
+const WinEstimate = 100000
+
+// Outcome returns WhiteWins or BlackWins once the side to move has lost, by having fewer than 3 pieces on
+// the board and in hand or by having no legal move, and Unfinished otherwise
+func (p Position) Outcome() string {
+	if p.Pieces(p.SideToMove) >= 3 && len(GenerateMoves(&p.Board, p.SideToMove, p.Phase())) > 0 {
+		return Unfinished
+	}
+	return lossFor(p.SideToMove)
+}
+
+// Pieces returns the number of pieces color has on the board and in hand
+func (p Position) Pieces(color int) int {
+	pieces := p.InHand(color)
+	for position := 0; position < 21; position++ {
+		if p.Board.GetPosition(position) == color {
+			pieces++
+		}
+	}
+	return pieces
+}
+
+func lossFor(color int) string {
+	if color == White {
+		return BlackWins
+	}
+	return WhiteWins
+}
+
+// SearchResult is the outcome of a search
+type SearchResult struct {
+	Move     Move   // Best move, To is NoSquare if the game is over
+	Estimate int    // Estimate of the position, White's point of view
+	Nodes    int    // Positions evaluated by static estimation
+	PV       []Move // Principal variation, starting with Move
+	Depth    int    // Depth of the search, of its last completed iteration for SearchLimits
+}
+
+// Search runs an alpha-beta search of the given depth below p with the evaluator at its leaves and returns
+// the first best move in generator order
+func Search(p Position, depth int, e Evaluator) SearchResult {
+	s := &searcher{evaluator: e}
+	estimate, pv := s.alphaBeta(p, depth, 0, math.MinInt32, math.MaxInt32)
+	return s.result(estimate, pv, depth)
+}
+
+type searcher struct {
+	evaluator Evaluator
+	nodes     int
+	miniMax   bool            // Search every move, without cutoffs
+	limited   bool            // Whether the limits below apply
+	visits    int             // Positions visited, to check the limits every so often
+	deadline  time.Time       // Time at which to abort, none if zero
+	stop      <-chan struct{} // Aborts once closed, if not nil
+	aborted   bool
+}
+
+func (s *searcher) result(estimate int, pv []Move, depth int) SearchResult {
+	result := SearchResult{Move: Move{From: NoSquare, To: NoSquare, Remove: NoSquare}, Estimate: estimate, Nodes: s.nodes, PV: pv, Depth: depth}
+	if len(pv) > 0 {
+		result.Move = pv[0]
+	}
+	return result
+}
+
+// checkLimits sets aborted once a limit is reached, looking every 1024 positions
+func (s *searcher) checkLimits() bool {
+	if !s.limited {
+		return false
+	}
+	s.visits++
+	if !s.aborted && s.visits%1024 == 0 {
+		s.aborted = s.limitReached()
+	}
+	return s.aborted
+}
+
+// limitReached reports whether the deadline has passed or stop is closed
+func (s *searcher) limitReached() bool {
+	if !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		return true
+	}
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *searcher) alphaBeta(p Position, depth int, ply int, alpha int, beta int) (int, []Move) {
+	if s.checkLimits() {
+		return 0, nil // Thrown away by SearchLimits
+	}
+	switch p.Outcome() {
+	case WhiteWins:
+		return WinEstimate - ply, nil // Prefer the quickest win and the slowest loss
+	case BlackWins:
+		return -WinEstimate + ply, nil
+	}
+	if depth == 0 {
+		s.nodes++
+		if pe, ok := s.evaluator.(PositionEvaluator); ok {
+			return pe.EvaluatePosition(p), nil
+		}
+		return s.evaluator.Evaluate(&p.Board, p.Phase(), p.SideToMove), nil
+	}
+
+	maximizing := p.SideToMove == White
+	bestEstimate := math.MaxInt32
+	if maximizing {
+		bestEstimate = math.MinInt32
+	}
+	var bestPV []Move
+	for _, m := range p.LegalMoves() {
+		estimate, pv := s.alphaBeta(p.played(m), depth-1, ply+1, alpha, beta)
+		if (maximizing && estimate > bestEstimate) || (!maximizing && estimate < bestEstimate) {
+			bestEstimate, bestPV = estimate, append([]Move{m}, pv...)
+		}
+		if maximizing {
+			alpha = max(alpha, estimate)
+		} else {
+			beta = min(beta, estimate)
+		}
+		if beta <= alpha && !s.miniMax {
+			break // Cutoff, the opponent avoids this position
+		}
+	}
+	return bestEstimate, bestPV
+}
+
+// --- Iterative deepening
+
+// Limits bound a search by SearchLimits. A search without any limit runs until stop is closed.
+type Limits struct {
+	Depth    int             // Deepest iteration, unbounded if 0
+	MoveTime time.Duration   // Time after which the search stops, unbounded if 0
+	Stop     <-chan struct{} // Stops the search once closed, if not nil
+	MiniMax  bool            // Search every move without alpha-beta cutoffs, for the same result more slowly
+	Info     func(SearchResult)
+}
+
+// SearchLimits searches p at depth 1, 2 and so on until a limit is reached or the game is decided within the
+// depth, calling Info after every iteration. It returns the result of the last completed iteration, its Nodes
+// counting every iteration; depth 1 is always completed, so that there is a move to play.
+func SearchLimits(p Position, e Evaluator, limits Limits) SearchResult {
+	s := &searcher{evaluator: e, miniMax: limits.MiniMax, stop: limits.Stop}
+	if limits.MoveTime > 0 {
+		s.deadline = time.Now().Add(limits.MoveTime)
+	}
+	var best SearchResult
+	for depth := 1; limits.Depth == 0 || depth <= limits.Depth; depth++ {
+		s.limited = depth > 1
+		if s.limited && s.limitReached() {
+			break
+		}
+		estimate, pv := s.alphaBeta(p, depth, 0, math.MinInt32, math.MaxInt32)
+		if s.aborted {
+			break
+		}
+		best = s.result(estimate, pv, depth)
+		if limits.Info != nil {
+			limits.Info(best)
+		}
+		if estimate > WinEstimate-1000 || estimate < -WinEstimate+1000 {
+			break // The game is decided within the depth, deeper searches find nothing new
+		}
+	}
+	best.Nodes = s.nodes
+	return best
+}
@@ -0,0 +1,101 @@
+package representation
+
+import (
+	"testing"
+	"time"
+)
+
+func mustParsePosition(t *testing.T, s string) Position {
+	t.Helper()
+	p, err := ParsePosition(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// Test that a game is lost with fewer than 3 pieces or without a legal move
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		position string
+		want     string
+	}{
+		{"xxxxxxxxxxxxxxxxxxxxx W 9 9 0 1", Unfinished},
+		{"WWxxxxxxxxxxxxxxxxBBB W 0 0 0 20", BlackWins},  // White has 2 pieces
+		{"WWxxxxxxxxxxxxxxxxBBB W 1 0 0 20", Unfinished}, // A third White piece is still in hand
+		{"WBBxxxBxxxxxxxxxxxxWW W 0 0 0 20", Unfinished}, // White can still move g6 and d6
+		{"WBBxxxBxxxxxxxxxxxxWW B 0 0 0 20", Unfinished}, // Black hops with 3 pieces
+		{"WWBxxxBxxxxBxBxxxBBWW W 0 0 0 20", BlackWins},  // Every White piece is blocked in
+		{"WWBxxxBxxxxBxBxxxBBWW B 0 0 0 20", Unfinished},
+	}
+	for _, test := range tests {
+		if got := mustParsePosition(t, test.position).Outcome(); got != test.want {
+			t.Errorf("Outcome of %s = %s, want %s", test.position, got, test.want)
+		}
+	}
+}
+
+// Test that Search closes a mill, finds the quickest win and returns a principal variation of full depth
+func TestSearch(t *testing.T) {
+	standard, _ := LookupEvaluator(DefaultEvaluator)
+
+	// White closes a0-b1-c2 and removes the Black piece outside the mill
+	p := mustParsePosition(t, "WxWxxxxxxxxxxxxxxxBBx W 7 7 0 3")
+	r := Search(p, 1, standard)
+	if r.Move.To != 4 || r.Move.Remove == NoSquare {
+		t.Errorf("Search played %s, want a mill on c2", r.Move.String())
+	}
+
+	// Removing a third Black piece wins at once, which is worth more than winning later
+	p = mustParsePosition(t, "WxWxxxxxxxxxxxxxxxBBB W 1 0 0 10")
+	r = Search(p, 3, standard)
+	if r.Estimate != WinEstimate-1 || r.Move.To != 4 {
+		t.Errorf("Search played %s with estimate %d, want c2 winning with %d", r.Move.String(), r.Estimate, WinEstimate-1)
+	}
+
+	p = StartPosition()
+	r = Search(p, 3, standard)
+	if len(r.PV) != 3 || r.PV[0] != r.Move || r.Nodes == 0 {
+		t.Errorf("Search from the start position: move %s, PV %v, %d nodes", r.Move.String(), r.PV, r.Nodes)
+	}
+	for _, m := range r.PV {
+		var err error
+		if p, err = p.Play(m); err != nil {
+			t.Errorf("principal variation %v is not legal: %v", r.PV, err)
+		}
+	}
+}
+
+// Test that iterative deepening ends on the same move as a search of the final depth, that minimax agrees
+// with alpha-beta, and that a time or stop limit still returns a move
+func TestSearchLimits(t *testing.T) {
+	standard, _ := LookupEvaluator(DefaultEvaluator)
+	p := mustParsePosition(t, "WBxBWxxWxxxxxxxxBxxWB W 0 0 0 20")
+
+	var depths []int
+	r := SearchLimits(p, standard, Limits{Depth: 3, Info: func(r SearchResult) { depths = append(depths, r.Depth) }})
+	want := Search(p, 3, standard)
+	if r.Move != want.Move || r.Estimate != want.Estimate || r.Depth != 3 || len(depths) != 3 || r.Nodes <= want.Nodes {
+		t.Errorf("deepening: %s %d at depth %d, %d nodes after %v; want %s %d", r.Move.String(), r.Estimate, r.Depth, r.Nodes, depths, want.Move.String(), want.Estimate)
+	}
+	if m := SearchLimits(p, standard, Limits{Depth: 3, MiniMax: true}); m.Move != want.Move || m.Estimate != want.Estimate || m.Nodes <= r.Nodes {
+		t.Errorf("minimax: %s %d, %d nodes", m.Move.String(), m.Estimate, m.Nodes)
+	}
+
+	start := time.Now()
+	r = SearchLimits(StartPosition(), standard, Limits{MoveTime: 50 * time.Millisecond})
+	if elapsed := time.Since(start); elapsed > time.Second || r.Depth < 1 || r.Move.To == NoSquare {
+		t.Errorf("timed search: depth %d, move %s after %v", r.Depth, r.Move.String(), elapsed)
+	}
+	stop := make(chan struct{})
+	close(stop)
+	if r = SearchLimits(StartPosition(), standard, Limits{Stop: stop}); r.Depth != 1 || r.Move.To == NoSquare {
+		t.Errorf("stopped search: depth %d, move %s", r.Depth, r.Move.String())
+	}
+
+	// A won position ends the deepening
+	p = mustParsePosition(t, "WxWxxxxxxxxxxxxxxxBBB W 1 0 0 10")
+	if r = SearchLimits(p, standard, Limits{}); r.Depth != 1 || r.Estimate != WinEstimate-1 {
+		t.Errorf("won position: depth %d, estimate %d", r.Depth, r.Estimate)
+	}
+}
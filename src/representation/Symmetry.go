@@ -0,0 +1,162 @@
+package representation
+
+// --- Board symmetries
+//
+// A symmetry is a relabeling of the 21 positions that keeps every pair of neighbors (Neighbors) adjacent
+// and maps every mill (mills) onto a mill. Symmetric positions have the same game tree, so position counts
+// only need one of them. The group is computed from the tables, not assumed from the drawing of the board:
+// with the Neighbors and mills tables as they stand it holds only the identity, so there is nothing to merge
+// in the searchers or key position tables by.
+
+// Symmetry maps every position i to the position Symmetry[i]
+type Symmetry [21]int
+
+var symmetries = computeSymmetries()
+
+// Symmetries returns the automorphism group of the board graph, the identity first
+func Symmetries() []Symmetry {
+	return append([]Symmetry(nil), symmetries...)
+}
+
+func computeSymmetries() []Symmetry {
+	var adjacent [21][21]bool
+	for i := 0; i < 21; i++ {
+		for _, j := range Neighbors(i) {
+			adjacent[i][j] = true
+		}
+	}
+	isMill := map[[3]int]bool{}
+	for _, mill := range mills {
+		isMill[sortedTriple(mill[0], mill[1], mill[2])] = true
+	}
+
+	var found []Symmetry
+	var sym Symmetry
+	var used [21]bool
+
+	// Assign images in position order, checking every neighbor pair and mill as soon as it is fully mapped
+	var extend func(i int)
+	extend = func(i int) {
+		if i == 21 {
+			found = append(found, sym)
+			return
+		}
+		for image := 0; image < 21; image++ {
+			if used[image] || len(Neighbors(image)) != len(Neighbors(i)) {
+				continue
+			}
+			sym[i] = image
+			if !consistent(i, &sym, &adjacent, isMill) {
+				continue
+			}
+			used[image] = true
+			extend(i + 1)
+			used[image] = false
+		}
+	}
+	extend(0)
+
+	return found
+}
+
+// consistent checks the pairs and mills whose positions are all at most i against the partial symmetry
+func consistent(i int, sym *Symmetry, adjacent *[21][21]bool, isMill map[[3]int]bool) bool {
+	for j := 0; j < i; j++ {
+		if adjacent[i][j] != adjacent[sym[i]][sym[j]] {
+			return false
+		}
+	}
+	for _, mill := range mills {
+		if mill[0] == i && mill[1] <= i && mill[2] <= i && !isMill[sortedTriple(sym[mill[0]], sym[mill[1]], sym[mill[2]])] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedTriple(a, b, c int) [3]int {
+	if a > b {
+		a, b = b, a
+	}
+	if b > c {
+		b, c = c, b
+	}
+	if a > b {
+		a, b = b, a
+	}
+	return [3]int{a, b, c}
+}
+
+// Transform returns the board with every piece moved from position i to position sym[i]
+func Transform(board *MorrisBoard, sym Symmetry) *MorrisBoard {
+	transformed := &MorrisBoard{}
+	for i := 0; i < 21; i++ {
+		transformed.SetPosition(sym[i], board.GetPosition(i))
+	}
+	return transformed
+}
+
+// Key returns the board packed into a single integer, suitable as a map key
+func (b *MorrisBoard) Key() uint64 {
+	return uint64(b.firstHalf) | uint64(b.secondHalf)<<32
+}
+
+// Canonical returns the representative of the board's symmetry class, the image with the smallest Key,
+// and the symmetry that maps the board onto it
+func Canonical(board *MorrisBoard) (*MorrisBoard, Symmetry) {
+	best, bestSym := Transform(board, symmetries[0]), symmetries[0]
+	for _, sym := range symmetries[1:] {
+		if image := Transform(board, sym); image.Key() < best.Key() {
+			best, bestSym = image, sym
+		}
+	}
+	return best, bestSym
+}
+
+// canonicalKey returns the Key of the board's canonical form, the same for all symmetric boards
+func canonicalKey(board *MorrisBoard) uint64 {
+	canonical, _ := Canonical(board)
+	return canonical.Key()
+}
+
+// CountDistinct returns the number of distinct positions at the given depth below the board, color to
+// move in every ply of the phase, and how many of them remain once symmetric positions are merged
+func CountDistinct(board *MorrisBoard, color int, depth int, phase int) (int, int) {
+	level := map[uint64]*MorrisBoard{board.Key(): board}
+	for ply := 0; ply < depth; ply++ {
+		next := map[uint64]*MorrisBoard{}
+		for _, b := range level {
+			for _, move := range GenerateMoves(b, color, phase) {
+				next[move.Key()] = move
+			}
+		}
+		level, color = next, 3-color
+	}
+
+	canonical := map[uint64]bool{}
+	for _, b := range level {
+		canonical[canonicalKey(b)] = true
+	}
+	return len(level), len(canonical)
+}
+
+// CountDistinctPosition is CountDistinct following the pieces in hand, as PerftPosition does
+func CountDistinctPosition(p Position, depth int) (int, int) {
+	level := map[uint64]Position{p.Board.Key(): p}
+	for ply := 0; ply < depth; ply++ {
+		next := map[uint64]Position{}
+		for _, q := range level {
+			for _, m := range q.LegalMoves() {
+				played := q.played(m)
+				next[played.Board.Key()] = played // Pieces in hand only depend on the depth, the board tells positions apart
+			}
+		}
+		level = next
+	}
+
+	canonical := map[uint64]bool{}
+	for _, q := range level {
+		canonical[canonicalKey(&q.Board)] = true
+	}
+	return len(level), len(canonical)
+}
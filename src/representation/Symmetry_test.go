@@ -0,0 +1,67 @@
+package representation
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Test that every symmetry found keeps neighbors adjacent and mills mills
+func TestSymmetriesAreAutomorphisms(t *testing.T) {
+	syms := Symmetries()
+	if len(syms) == 0 || syms[0] != (Symmetry{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}) {
+		t.Fatalf("Symmetries() does not start with the identity: %v", syms)
+	}
+
+	for _, sym := range syms {
+		for i := 0; i < 21; i++ {
+			for _, j := range Neighbors(i) {
+				adjacent := false
+				for _, k := range Neighbors(sym[i]) {
+					adjacent = adjacent || k == sym[j]
+				}
+				if !adjacent {
+					t.Errorf("%v maps neighbors %d and %d to %d and %d", sym, i, j, sym[i], sym[j])
+				}
+			}
+		}
+		for _, mill := range mills {
+			var b MorrisBoard
+			b.SetPosition(sym[mill[1]], White)
+			b.SetPosition(sym[mill[2]], White)
+			if !CloseMill(sym[mill[0]], &b, White) {
+				t.Errorf("%v does not map mill %v onto a mill", sym, mill)
+			}
+		}
+	}
+}
+
+// Test that symmetric boards share a canonical form and a game tree
+func TestCanonical(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		var b MorrisBoard
+		for position := 0; position < 21; position++ {
+			b.SetPosition(position, rng.Intn(3))
+		}
+		canonical, sym := Canonical(&b)
+		if *Transform(&b, sym) != *canonical {
+			t.Fatalf("Canonical(%s) returned a symmetry that does not map it onto %s", b.String(), canonical.String())
+		}
+		for _, s := range Symmetries() {
+			image := Transform(&b, s)
+			if canonicalKey(image) != canonical.Key() {
+				t.Fatalf("%s and its image %s have different canonical forms", b.String(), image.String())
+			}
+			if i < 20 && Perft(image, White, 2, MidgameEndgame) != Perft(&b, White, 2, MidgameEndgame) {
+				t.Fatalf("%s and its image %s have different game trees", b.String(), image.String())
+			}
+		}
+	}
+}
+
+func TestCountDistinct(t *testing.T) {
+	positions, canonical := CountDistinct(&MorrisBoard{}, White, 2, Opening)
+	if positions != 420 || canonical > positions || canonical*len(Symmetries()) < positions {
+		t.Errorf("CountDistinct(empty, 2) = %d, %d", positions, canonical)
+	}
+}
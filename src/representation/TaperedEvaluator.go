@@ -0,0 +1,163 @@
+package representation
+
+import "math"
+
+// --- Tapered evaluator
+//
+// StaticEstimateOpeningNaive and StaticEstimateMidgameEndgame are on different scales, so a search whose
+// tree crosses from the opening into the midgame sees its estimates jump. TaperedEvaluator instead blends
+// three sets of weights, for placing, moving and flying, by how far each side has come:
+//
+//	placing = pieces in hand / 9
+//	flying  = (1 - placing) * clamp((6 - pieces on board) / 3, 0, 1), all of it once down to 3 pieces
+//	moving  = 1 - placing - flying
+//
+// Each side scores, in every phase, the material it has on the board and in hand and the table value of the
+// squares it stands on. A placement moves a piece from hand to board and changes the blend by a ninth, so
+// the estimates change gradually across the phases; only removals move them by a whole piece.
+
+// Phases of the tapered evaluator's tables
+const (
+	Placing = 0
+	Moving  = 1
+	Flying  = 2
+)
+
+// taperedPhaseNames are the names of the phases in parameter names
+var taperedPhaseNames = [3]string{"placing", "moving", "flying"}
+
+// TaperedWeights are the weights of TaperedEvaluator, per phase
+type TaperedWeights struct {
+	Material [3]int     // Per piece on the board or in hand
+	Squares  [3][21]int // Per piece on each square
+}
+
+// DefaultTaperedWeights favor the junctions, with 4 neighbors, and avoid the corners with 2 while pieces
+// place and slide. Flying pieces reach every square, so their table is flat.
+var DefaultTaperedWeights = defaultTaperedWeights()
+
+func defaultTaperedWeights() TaperedWeights {
+	w := TaperedWeights{Material: [3]int{1000, 1000, 1000}}
+	byNeighbors := [3]map[int]int{
+		Placing: {2: -20, 3: 0, 4: 30},
+		Moving:  {2: -30, 3: 0, 4: 40},
+		Flying:  {2: 0, 3: 0, 4: 0},
+	}
+	for phase := range w.Squares {
+		for position := 0; position < 21; position++ {
+			w.Squares[phase][position] = byNeighbors[phase][len(Neighbors(position))]
+		}
+	}
+	return w
+}
+
+// TaperedEvaluator evaluates positions with TaperedWeights, blended by the pieces in hand and on the board
+type TaperedEvaluator struct {
+	Weights TaperedWeights
+}
+
+func init() {
+	RegisterEvaluator("tapered", &TaperedEvaluator{Weights: DefaultTaperedWeights})
+}
+
+// TaperedBlend returns the weights of the placing, moving and flying phases for a side with the given pieces
+// in hand and on the board. They add up to 1. Flying fades in as the side goes from 6 pieces to the 3 it
+// flies with, counting those still in hand.
+func TaperedBlend(inHand int, onBoard int) [3]float64 {
+	placing := float64(inHand) / PiecesPerSide
+	flying := (1 - placing) * math.Min(math.Max(float64(6-onBoard-inHand)/3, 0), 1)
+	return [3]float64{Placing: placing, Moving: 1 - placing - flying, Flying: flying}
+}
+
+// score returns the blended score of color
+func (e *TaperedEvaluator) score(p *Position, color int) float64 {
+	var squares [3]int
+	onBoard := 0
+	for position := 0; position < 21; position++ {
+		if p.Board.GetPosition(position) == color {
+			onBoard++
+			for phase := range squares {
+				squares[phase] += e.Weights.Squares[phase][position]
+			}
+		}
+	}
+
+	blend := TaperedBlend(p.InHand(color), onBoard)
+	pieces := onBoard + p.InHand(color)
+	score := 0.0
+	for phase, weight := range blend {
+		score += weight * float64(e.Weights.Material[phase]*pieces+squares[phase])
+	}
+	return score
+}
+
+// EvaluatePosition implements PositionEvaluator
+func (e *TaperedEvaluator) EvaluatePosition(p Position) int {
+	return int(math.Round(e.score(&p, White) - e.score(&p, Black)))
+}
+
+// Evaluate implements Evaluator. A bare board holds no pieces in hand, so it is completed with
+// LegacyPosition, which takes every piece not on the board in the opening to be still in hand.
+func (e *TaperedEvaluator) Evaluate(board *MorrisBoard, phase int, sideToMove int) int {
+	return e.EvaluatePosition(LegacyPosition(*board, sideToMove, phase))
+}
+
+// Params implements Tunable: the material of every phase, then its squares, named like "moving.b3"
+func (e *TaperedEvaluator) Params() []Param {
+	var params []Param
+	for phase, name := range taperedPhaseNames {
+		params = append(params, Param{Name: name + ".material", Value: e.Weights.Material[phase]})
+	}
+	for phase, name := range taperedPhaseNames {
+		for position := 0; position < 21; position++ {
+			params = append(params, Param{Name: name + "." + SquareName(position), Value: e.Weights.Squares[phase][position]})
+		}
+	}
+	return params
+}
+
+// WithParams implements Tunable
+func (e *TaperedEvaluator) WithParams(values []int) Tunable {
+	var w TaperedWeights
+	copy(w.Material[:], values[:3])
+	for phase := range w.Squares {
+		copy(w.Squares[phase][:], values[3+21*phase:3+21*(phase+1)])
+	}
+	return &TaperedEvaluator{Weights: w}
+}
+
+// Explain implements Explainer, with the blended material and squares of every phase, rounded, and the
+// rounding that makes them add up to the estimate
+func (e *TaperedEvaluator) Explain(board *MorrisBoard, phase int, sideToMove int) Explanation {
+	p := LegacyPosition(*board, sideToMove, phase)
+	var material, squares [3]float64
+	for _, color := range []int{White, Black} {
+		sign := 1.0
+		if color == Black {
+			sign = -1
+		}
+		var squareSums [3]int
+		onBoard := 0
+		for position := 0; position < 21; position++ {
+			if p.Board.GetPosition(position) == color {
+				onBoard++
+				for table := range squareSums {
+					squareSums[table] += e.Weights.Squares[table][position]
+				}
+			}
+		}
+		pieces := onBoard + p.InHand(color)
+		for table, weight := range TaperedBlend(p.InHand(color), onBoard) {
+			material[table] += sign * weight * float64(e.Weights.Material[table]*pieces)
+			squares[table] += sign * weight * float64(squareSums[table])
+		}
+	}
+
+	var x Explanation
+	for table, name := range taperedPhaseNames {
+		x.add("material, "+name, int(math.Round(material[table])), 1)
+		x.add("squares, "+name, int(math.Round(squares[table])), 1)
+	}
+	x.add("rounding", e.EvaluatePosition(p)-x.Total, 1)
+	return x
+}
@@ -0,0 +1,111 @@
+package representation
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Test that the blend starts in the placing phase, moves on with every placement and flies at 3 pieces
+func TestTaperedBlend(t *testing.T) {
+	tests := []struct {
+		inHand, onBoard int
+		want            [3]float64
+	}{
+		{9, 0, [3]float64{1, 0, 0}},
+		{0, 9, [3]float64{0, 1, 0}},
+		{0, 6, [3]float64{0, 1, 0}},
+		{0, 3, [3]float64{0, 0, 1}},
+		{3, 3, [3]float64{1.0 / 3, 2.0 / 3, 0}},
+		{8, 1, [3]float64{8.0 / 9, 1.0 / 9, 0}},
+		{5, 4, [3]float64{5.0 / 9, 4.0 / 9, 0}},
+		{2, 1, [3]float64{2.0 / 9, 0, 7.0 / 9}},
+	}
+	for _, test := range tests {
+		got := TaperedBlend(test.inHand, test.onBoard)
+		for phase := range got {
+			if math.Abs(got[phase]-test.want[phase]) > 1e-9 {
+				t.Errorf("TaperedBlend(%d, %d) = %v, want %v", test.inHand, test.onBoard, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+// Test that no weight goes to flying in the opening, unless captures have left the side with fewer than 6
+// pieces on the board and in hand
+func TestTaperedBlendOpening(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for game := 0; game < 50; game++ {
+		p := StartPosition()
+		for p.WhiteInHand+p.BlackInHand > 0 && p.Outcome() == Unfinished {
+			for _, color := range []int{White, Black} {
+				onBoard := countPieces(&p.Board, color)
+				blend := TaperedBlend(p.InHand(color), onBoard)
+				if pieces := p.InHand(color) + onBoard; (blend[Flying] > 0) != (pieces < 6) {
+					t.Fatalf("%s: %s with %d pieces flies by %v", p, colorName(color), pieces, blend[Flying])
+				}
+			}
+			moves := p.LegalMoves()
+			p, _ = p.Play(moves[rng.Intn(len(moves))])
+		}
+	}
+}
+
+// Test that the estimates change by less than a piece with every move that removes nothing, across the last
+// placement and the first flights, where the standard evaluator changes its scale
+func TestTaperedContinuity(t *testing.T) {
+	e := &TaperedEvaluator{Weights: DefaultTaperedWeights}
+	rng := rand.New(rand.NewSource(1))
+	for game := 0; game < 50; game++ {
+		p := StartPosition()
+		previous := e.EvaluatePosition(p)
+		for ply := 0; ply < 120 && p.Outcome() == Unfinished; ply++ {
+			moves := p.LegalMoves()
+			m := moves[rng.Intn(len(moves))]
+			next, err := p.Play(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			estimate := e.EvaluatePosition(next)
+			if m.Remove == NoSquare && abs(estimate-previous) > 200 {
+				t.Fatalf("%s: %s changes the estimate from %d to %d", p, m, previous, estimate)
+			}
+			p, previous = next, estimate
+		}
+	}
+}
+
+// Test the explanation of the evaluator and that it reads and writes its tables
+func TestTaperedExplainAndParams(t *testing.T) {
+	e := &TaperedEvaluator{Weights: DefaultTaperedWeights}
+	board := mustParseBoard(t, "WWBxxxBxxxxBxBxxxBBWW")
+	x := e.Explain(&board, MidgameEndgame, White)
+	if got := x.Terms[len(x.Terms)-1]; got.Name != "rounding" || abs(got.Value) > 3 {
+		t.Errorf("rounding term %+v", got)
+	}
+	if moving := x.Terms[2]; moving.Name != "material, moving" || moving.Value != -4667 {
+		t.Errorf("moving material term %+v, want a third of 4 White pieces against 6 Black", moving)
+	}
+
+	params := e.Params()
+	if len(params) != 3+3*21 || params[3].Name != "placing.a0" {
+		t.Fatalf("Params = %d parameters starting %v", len(params), params[:4])
+	}
+	values := make([]int, len(params))
+	for i, p := range params {
+		values[i] = p.Value
+	}
+	values[3+21*Moving+5] = 999
+	changed := e.WithParams(values).(*TaperedEvaluator)
+	if changed.Weights.Squares[Moving][5] != 999 || changed.Weights.Material != e.Weights.Material {
+		t.Errorf("WithParams = %+v", changed.Weights)
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,85 @@
+package representation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test that weights written to a file read back into the same evaluator
+func TestWeightsRoundTrip(t *testing.T) {
+	for _, name := range []string{"opening", "midgame"} {
+		e, _ := LookupEvaluator(name)
+		values := []int{}
+		for i := range e.(Tunable).Params() {
+			values = append(values, 3*i-5)
+		}
+		tuned := e.(Tunable).WithParams(values)
+
+		var sb strings.Builder
+		if err := WriteWeights(&sb, name, tuned, "tuned\nby hand"); err != nil {
+			t.Fatal(err)
+		}
+		read, readName, err := ReadWeights(strings.NewReader(sb.String()))
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, sb.String())
+		}
+		if readName != name {
+			t.Errorf("read evaluator %s, want %s", readName, name)
+		}
+		for i, p := range read.Params() {
+			if p.Value != values[i] {
+				t.Errorf("%s: %s = %d, want %d", name, p.Name, p.Value, values[i])
+			}
+		}
+	}
+}
+
+// Test that missing weights keep their defaults and that bad lines are reported with their number
+func TestReadWeights(t *testing.T) {
+	read, _, err := ReadWeights(strings.NewReader("# comment\n\nevaluator midgame\nBlackMoves -7\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := read.(*MidgameEvaluator).Weights; w != (MidgameWeights{Material: 1000, WhiteMoves: 0, BlackMoves: -7, Mills: 0}) {
+		t.Errorf("weights = %+v", w)
+	}
+
+	for text, line := range map[string]int{
+		"Material 3\n":                      1,
+		"evaluator nope\n":                  1,
+		"evaluator standard\n":              1,
+		"evaluator midgame\nMaterial x\n":   2,
+		"evaluator midgame\n\nSpeed 3\n":    3,
+		"evaluator midgame\nMaterial 1 2\n": 2,
+		"# only a comment\n":                0,
+	} {
+		var weightsErr *WeightsError
+		if _, _, err := ReadWeights(strings.NewReader(text)); !errors.As(err, &weightsErr) || weightsErr.Line != line {
+			t.Errorf("ReadWeights(%q) error = %v, want a WeightsError on line %d", text, err, line)
+		}
+	}
+}
+
+// Test that --weights selects the evaluator of the file with its weights
+func TestParseEvaluatorFlagWeights(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "midgame.weights")
+	if err := os.WriteFile(path, []byte("evaluator midgame\nMaterial 1\nBlackMoves 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	board := MorrisBoardFromString("xBxBWxxxxBxBWWxWWWBBB")
+	e, rest, err := ParseEvaluatorFlag([]string{"in.txt", "--weights", path, "out.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := countPieces(board, White) - countPieces(board, Black); strings.Join(rest, " ") != "in.txt out.txt" || e.Evaluate(board, MidgameEndgame, White) != want {
+		t.Errorf("ParseEvaluatorFlag selected %v with args %v", e, rest)
+	}
+
+	if _, _, err := ParseEvaluatorFlag([]string{"--eval", "opening", "--weights=" + path}); err == nil {
+		t.Errorf("ParseEvaluatorFlag accepted weights for another evaluator than --eval")
+	}
+}
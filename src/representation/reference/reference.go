@@ -0,0 +1,182 @@
+// Package reference is a deliberately simple Morris-B move generator, written from the rules
+// rather than for speed. It shares no code with the representation package so that the two
+// can be checked against each other.
+package reference
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Lines lists every mill of the board, each as its three squares
+var Lines = [][3]int{
+	{0, 6, 18}, {0, 2, 4}, {1, 11, 20}, {2, 7, 15}, {3, 10, 17}, {4, 8, 12}, {5, 9, 14},
+	{6, 7, 8}, {9, 10, 11}, {12, 13, 14}, {13, 16, 19}, {15, 16, 17}, {18, 19, 20},
+}
+
+// Edges lists every pair of adjacent squares once
+var Edges = [][2]int{
+	{0, 1}, {0, 2}, {0, 6}, {1, 11}, {2, 4}, {2, 7}, {3, 4}, {3, 5}, {3, 10}, {4, 5}, {4, 8},
+	{5, 9}, {6, 7}, {6, 18}, {7, 8}, {7, 15}, {8, 12}, {9, 10}, {9, 14}, {10, 11}, {10, 17},
+	{11, 20}, {12, 13}, {12, 16}, {13, 14}, {13, 19}, {14, 15}, {15, 17}, {16, 17}, {16, 18},
+	{17, 19}, {18, 20}, {19, 20},
+}
+
+// Board squares hold one of these characters, as in the 21 character board strings
+const (
+	Empty = 'x'
+	White = 'W'
+	Black = 'B'
+)
+
+// Opponent returns the other color
+func Opponent(color byte) byte {
+	if color == White {
+		return Black
+	}
+	return White
+}
+
+func adjacent(a, b int) bool {
+	for _, e := range Edges {
+		if (e[0] == a && e[1] == b) || (e[0] == b && e[1] == a) {
+			return true
+		}
+	}
+	return false
+}
+
+// inMill reports whether square i is part of a line fully occupied by color
+func inMill(board []byte, i int, color byte) bool {
+	for _, line := range Lines {
+		if (line[0] == i || line[1] == i || line[2] == i) &&
+			board[line[0]] == color && board[line[1]] == color && board[line[2]] == color {
+			return true
+		}
+	}
+	return false
+}
+
+func count(board []byte, color byte) int {
+	n := 0
+	for _, c := range board {
+		if c == color {
+			n++
+		}
+	}
+	return n
+}
+
+// finish completes a move of color that just arrived on square to. Closing a mill removes one
+// opponent piece, which must not stand in a mill unless all of them do.
+func finish(board []byte, to int, color byte) []string {
+	if !inMill(board, to, color) {
+		return []string{string(board)}
+	}
+
+	opponent := Opponent(color)
+	var free, all []int
+	for i, c := range board {
+		if c == opponent {
+			all = append(all, i)
+			if !inMill(board, i, opponent) {
+				free = append(free, i)
+			}
+		}
+	}
+	if len(all) == 0 {
+		return []string{string(board)} // Nothing left to remove
+	}
+	if len(free) == 0 {
+		free = all
+	}
+
+	var out []string
+	for _, i := range free {
+		removed := append([]byte(nil), board...)
+		removed[i] = Empty
+		out = append(out, string(removed))
+	}
+	return out
+}
+
+// Placements returns every board reachable by color placing a piece from hand, sorted
+func Placements(board string, color byte) []string {
+	var out []string
+	for to := 0; to < len(board); to++ {
+		if board[to] != Empty {
+			continue
+		}
+		next := []byte(board)
+		next[to] = color
+		out = append(out, finish(next, to, color)...)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Moves returns every board reachable by color sliding a piece to an adjacent empty square,
+// or hopping it to any empty square when color has exactly three pieces left, sorted
+func Moves(board string, color byte) []string {
+	hopping := count([]byte(board), color) == 3
+
+	var out []string
+	for from := 0; from < len(board); from++ {
+		if board[from] != color {
+			continue
+		}
+		for to := 0; to < len(board); to++ {
+			if board[to] != Empty || (!hopping && !adjacent(from, to)) {
+				continue
+			}
+			next := []byte(board)
+			next[from] = Empty
+			next[to] = color
+			out = append(out, finish(next, to, color)...)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// RandomPosition plays random legal moves from the empty board and returns the board reached and
+// the side to move. The first placements plies are placements, the following moves plies are
+// slides or hops; play stops early once the side to move has lost.
+func RandomPosition(rng *rand.Rand, placements int, moves int) (string, byte) {
+	board, color := "xxxxxxxxxxxxxxxxxxxxx", byte(White)
+
+	for ply := 0; ply < placements; ply++ {
+		next := Placements(board, color)
+		board, color = next[rng.Intn(len(next))], Opponent(color)
+	}
+	for ply := 0; ply < moves; ply++ {
+		if count([]byte(board), color) < 3 {
+			break
+		}
+		next := Moves(board, color)
+		if len(next) == 0 {
+			break
+		}
+		board, color = next[rng.Intn(len(next))], Opponent(color)
+	}
+	return board, color
+}
+
+// Minimize empties squares of board one at a time for as long as fails keeps reporting true,
+// returning a board on which fails holds but no longer does with any single further piece removed
+func Minimize(board string, fails func(string) bool) string {
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for i := 0; i < len(board); i++ {
+			if board[i] == Empty {
+				continue
+			}
+			candidate := []byte(board)
+			candidate[i] = Empty
+			if fails(string(candidate)) {
+				board, shrunk = string(candidate), true
+			}
+		}
+	}
+	return board
+}
@@ -0,0 +1,145 @@
+package reference
+
+import (
+	"fmt"
+	"math/rand"
+	"representation"
+	"sort"
+	"testing"
+)
+
+// production runs the representation generator for the phase on a board string
+func production(board string, color byte, opening bool) []string {
+	b := representation.MorrisBoardFromString(board)
+	c := representation.White
+	if color == Black {
+		c = representation.Black
+	}
+
+	var boards []*representation.MorrisBoard
+	if opening {
+		boards = representation.GenerateAdd(b, c)
+	} else {
+		boards = representation.GenerateMovesMidgameEndgame(b, c)
+	}
+
+	out := make([]string, 0, len(boards))
+	for _, next := range boards {
+		out = append(out, next.String())
+	}
+	sort.Strings(out)
+	return out
+}
+
+func slow(board string, color byte, opening bool) []string {
+	if opening {
+		return Placements(board, color)
+	}
+	return Moves(board, color)
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// difference returns the boards of a that are missing from b, both sorted
+func difference(a, b []string) []string {
+	var out []string
+	for _, s := range a {
+		if i := sort.SearchStrings(b, s); i == len(b) || b[i] != s {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// check compares both generators on board and reports a minimal reproducing board on mismatch
+func check(t *testing.T, board string, color byte, opening bool) {
+	t.Helper()
+	fails := func(b string) bool { return !equal(production(b, color, opening), slow(b, color, opening)) }
+	if !fails(board) {
+		return
+	}
+
+	minimal := Minimize(board, fails)
+	got, want := production(minimal, color, opening), slow(minimal, color, opening)
+	phase := "midgame"
+	if opening {
+		phase = "opening"
+	}
+	t.Errorf("%s generator mismatch for %c on %s (found on %s)\n  missing: %v\n  extra:   %v",
+		phase, color, minimal, board, difference(want, got), difference(got, want))
+}
+
+// Test that Neighbors describes the same graph as Edges
+func TestNeighborsMatchEdges(t *testing.T) {
+	for a := 0; a < 21; a++ {
+		for b := 0; b < 21; b++ {
+			isNeighbor := false
+			for _, n := range representation.Neighbors(a) {
+				isNeighbor = isNeighbor || n == b
+			}
+			if isNeighbor != adjacent(a, b) {
+				t.Errorf("squares %d and %d: Neighbors says %v, Edges says %v", a, b, isNeighbor, adjacent(a, b))
+			}
+		}
+	}
+}
+
+func TestGenerateAddMatchesReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	positions := 2000
+	if testing.Short() {
+		positions = 200
+	}
+
+	for i := 0; i < positions; i++ {
+		board, _ := RandomPosition(rng, rng.Intn(18), 0)
+		for _, color := range []byte{White, Black} {
+			check(t, board, color, true)
+		}
+		if t.Failed() {
+			return
+		}
+	}
+}
+
+func TestGenerateMovesMidgameEndgameMatchesReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	positions := 2000
+	if testing.Short() {
+		positions = 200
+	}
+
+	for i := 0; i < positions; i++ {
+		board, _ := RandomPosition(rng, 18, rng.Intn(60))
+		for _, color := range []byte{White, Black} {
+			check(t, board, color, false)
+		}
+		if t.Failed() {
+			return
+		}
+	}
+}
+
+func ExampleMinimize() {
+	// The smallest board on which White still has a placement that closes a mill
+	closesMill := func(b string) bool {
+		for _, next := range Placements(b, White) {
+			if count([]byte(next), Black) < count([]byte(b), Black) {
+				return true
+			}
+		}
+		return false
+	}
+	fmt.Println(Minimize("WWxBBxWxxBxxxxxxBxWxx", closesMill))
+	// Output: xxxxxxWxxxxxxxxxBxWxx
+}
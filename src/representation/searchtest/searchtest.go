@@ -0,0 +1,129 @@
+// Package searchtest checks game tree searchers against a plain minimax search over the same
+// move generator and static estimate, on random reachable positions.
+package searchtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"representation"
+	"representation/reference"
+	"strings"
+	"testing"
+)
+
+// Searcher returns the root estimate of a search of the given depth, with player to move
+type Searcher func(board *representation.MorrisBoard, player int, depth int, maximizingPlayer bool) int
+
+// Config describes the game the searcher plays and the corpus it is checked on
+type Config struct {
+	Opening   bool                                                                             // Corpus positions are taken from the opening, otherwise from the midgame
+	Positions int                                                                              // Number of random positions
+	MaxDepth  int                                                                              // Every depth from 1 to MaxDepth is searched
+	Seed      int64                                                                            // Seed of the random corpus
+	Generate  func(board *representation.MorrisBoard, color int) []*representation.MorrisBoard // Move generator
+	Evaluator representation.Evaluator                                                         // Static estimate, White's point of view
+	DumpDepth int                                                                              // Levels of the minimax tree printed on divergence
+}
+
+// Node is a position of a fully expanded minimax tree
+type Node struct {
+	Board    *representation.MorrisBoard
+	Estimate int
+	Children []*Node
+}
+
+// Minimax expands the whole game tree of the given depth and backs up the estimates. A position
+// without moves keeps the initial estimate of the side to move, as the searchers in this repo do.
+func Minimax(board *representation.MorrisBoard, player int, depth int, maximizingPlayer bool, cfg Config) *Node {
+	node := &Node{Board: board}
+	if depth == 0 {
+		phase := representation.MidgameEndgame
+		if cfg.Opening {
+			phase = representation.Opening
+		}
+		node.Estimate = cfg.Evaluator.Evaluate(board, phase, player)
+		return node
+	}
+
+	if maximizingPlayer {
+		node.Estimate = math.MinInt32
+	} else {
+		node.Estimate = math.MaxInt32
+	}
+	for _, move := range cfg.Generate(board, player) {
+		child := Minimax(move, 3-player, depth-1, !maximizingPlayer, cfg)
+		node.Children = append(node.Children, child)
+		if (maximizingPlayer && child.Estimate > node.Estimate) || (!maximizingPlayer && child.Estimate < node.Estimate) {
+			node.Estimate = child.Estimate
+		}
+	}
+	return node
+}
+
+// Dump prints the tree below n down to the given number of levels, one position per line
+func (n *Node) Dump(levels int) string {
+	var sb strings.Builder
+	n.dump(&sb, 0, levels)
+	return sb.String()
+}
+
+func (n *Node) dump(sb *strings.Builder, indent int, levels int) {
+	fmt.Fprintf(sb, "%s%s %d\n", strings.Repeat("  ", indent), n.Board.String(), n.Estimate)
+	if levels == 0 {
+		if len(n.Children) > 0 {
+			fmt.Fprintf(sb, "%s... %d moves\n", strings.Repeat("  ", indent+1), len(n.Children))
+		}
+		return
+	}
+	for _, child := range n.Children {
+		child.dump(sb, indent+1, levels-1)
+	}
+}
+
+// Corpus returns random positions reachable from the empty board
+func Corpus(cfg Config) []*representation.MorrisBoard {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	boards := make([]*representation.MorrisBoard, 0, cfg.Positions)
+	for len(boards) < cfg.Positions {
+		var board string
+		if cfg.Opening {
+			board, _ = reference.RandomPosition(rng, rng.Intn(16), 0)
+		} else {
+			board, _ = reference.RandomPosition(rng, 18, rng.Intn(40))
+		}
+		boards = append(boards, representation.MorrisBoardFromString(board))
+	}
+	return boards
+}
+
+// CheckEquivalent runs search and plain minimax side by side over the corpus, for White as the
+// maximizing player and Black as the minimizing player, and fails on the first root estimate
+// that differs, dumping the minimax tree of that position
+func CheckEquivalent(t testing.TB, cfg Config, search Searcher) {
+	t.Helper()
+	if cfg.DumpDepth == 0 {
+		cfg.DumpDepth = 1
+	}
+
+	sides := []struct {
+		player     int
+		maximizing bool
+		name       string
+	}{
+		{representation.White, true, "White (maximizing)"},
+		{representation.Black, false, "Black (minimizing)"},
+	}
+
+	for depth := 1; depth <= cfg.MaxDepth; depth++ {
+		for _, board := range Corpus(cfg) {
+			for _, side := range sides {
+				tree := Minimax(board, side.player, depth, side.maximizing, cfg)
+				if got := search(board, side.player, depth, side.maximizing); got != tree.Estimate {
+					t.Fatalf("position %s, %s to move, depth %d: search estimate %d, minimax estimate %d\nminimax tree:\n%s",
+						board.String(), side.name, depth, got, tree.Estimate, tree.Dump(cfg.DumpDepth))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"representation"
+)
+
+/*
+Requirements:
+
+ 1. get command line args
+    X --eval NAME (a registered evaluator with weights) or --weights FILE (a weights file to start from)
+    X --checkpoint FILE (the state of the run, resumed from if it exists, written after every iteration)
+    X --out FILE (optional, the weights file written at the end)
+    X --iterations, --pairs, --depth, --max-plies, --opening-plies, --concurrency, --perturb, --rate, --seed
+      (optional, see DefaultSettings)
+
+2. output:
+  - for every iteration, the points of the +c and -c engines and the parameters after the update
+  	- command line
+  	- checkpoint file
+  - the tuned parameters
+  	- command line
+  	- weights file, readable by every CLI with --weights FILE
+
+The search has no extensions, contempt or quiescence yet, so the parameters tuned are those of the evaluator.
+A resumed run keeps the settings of its checkpoint, except for the number of iterations when --iterations is
+given. The step schedule stays that of the first run.
+*/
+
+func SpsaMain() error {
+	d := DefaultSettings
+	flags := flag.NewFlagSet("Spsa", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	evalName := flags.String("eval", "opening", "evaluator to tune")
+	weights := flags.String("weights", "", "weights file to start from")
+	checkpoint := flags.String("checkpoint", "", "checkpoint file")
+	out := flags.String("out", "", "weights file to write")
+	iterations := flags.Int("iterations", d.Iterations, "iterations in total")
+	pairs := flags.Int("pairs", d.GamePairs, "game pairs per iteration")
+	depth := flags.Int("depth", d.Depth, "search depth")
+	maxPlies := flags.Int("max-plies", d.MaxPlies, "plies after which a game is drawn")
+	openingPlies := flags.Int("opening-plies", d.OpeningPly, "random plies of every opening")
+	concurrency := flags.Int("concurrency", d.Concurrency, "games played at the same time")
+	perturb := flags.Float64("perturb", d.Perturb, "perturbation as a fraction of the starting value")
+	rate := flags.Float64("rate", d.Rate, "step in perturbations per point won")
+	seed := flags.Int64("seed", d.Seed, "random seed")
+	usage := fmt.Errorf("usage: Spsa [--eval NAME | --weights FILE] --checkpoint FILE [--out FILE] [--iterations N] [--pairs N] [--depth N] [--max-plies N] [--opening-plies N] [--concurrency N] [--perturb F] [--rate F] [--seed N]")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if *checkpoint == "" || flags.NArg() > 0 {
+		return usage
+	}
+
+	// Resume from the checkpoint if there is one, otherwise start from the evaluator's weights
+	cp, err := LoadCheckpoint(*checkpoint)
+	switch {
+	case err == nil:
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name == "iterations" {
+				cp.Settings.Iterations = *iterations
+			}
+		})
+		fmt.Printf("Resuming %s from iteration %d\n", cp.Evaluator, cp.Iteration)
+	case errors.Is(err, fs.ErrNotExist):
+		tunable, name, err := startingPoint(*evalName, *weights)
+		if err != nil {
+			return err
+		}
+		cp = NewCheckpoint(name, tunable, Settings{
+			Iterations: *iterations, GamePairs: *pairs, Depth: *depth, MaxPlies: *maxPlies, OpeningPly: *openingPlies,
+			Concurrency: *concurrency, Perturb: *perturb, Rate: *rate, Seed: *seed,
+		})
+		fmt.Printf("Tuning %s from %s\n", name, formatParams(cp.Names, cp.Values()))
+	default:
+		return err
+	}
+
+	e, err := representation.LookupEvaluator(cp.Evaluator)
+	if err != nil {
+		return err
+	}
+	tunable, ok := e.(representation.Tunable)
+	if !ok {
+		return fmt.Errorf("evaluator %s has no weights to tune", cp.Evaluator)
+	}
+
+	for cp.Iteration < cp.Settings.Iterations {
+		it := cp.Step(tunable)
+		fmt.Printf("iteration %d: +c %.1f, -c %.1f, %s\n", cp.Iteration, it.Plus, it.Minus, formatParams(cp.Names, cp.Values()))
+		if err := cp.Save(*checkpoint); err != nil {
+			return fmt.Errorf("failed to write checkpoint: %v", err)
+		}
+	}
+	fmt.Printf("Tuned: %s\n", formatParams(cp.Names, cp.Values()))
+
+	// Write the tuned weights file
+	if *out == "" {
+		return nil
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to write weights file: %v", err)
+	}
+	comment := fmt.Sprintf("Tuned by SPSA, %d iterations of %d game pairs at depth %d", cp.Iteration, cp.Settings.GamePairs, cp.Settings.Depth)
+	if err := representation.WriteWeights(f, cp.Evaluator, tunable.WithParams(cp.Values()), comment); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// startingPoint returns the evaluator to tune with its starting weights, and its name
+func startingPoint(evalName string, weights string) (representation.Tunable, string, error) {
+	if weights != "" {
+		return representation.LoadWeights(weights)
+	}
+	e, err := representation.LookupEvaluator(evalName)
+	if err != nil {
+		return nil, "", err
+	}
+	t, ok := e.(representation.Tunable)
+	if !ok {
+		return nil, "", fmt.Errorf("evaluator %s has no weights to tune", evalName)
+	}
+	return t, evalName, nil
+}
+
+func formatParams(names []string, values []int) string {
+	s := ""
+	for i, name := range names {
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("%s=%d", name, values[i])
+	}
+	return s
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"representation"
+	"sync"
+)
+
+// --- SPSA self-play tuning
+//
+// Simultaneous perturbation stochastic approximation moves all parameters at once. Every iteration draws
+// a random sign for each parameter, builds one engine with the parameters shifted by +c in those directions
+// and one shifted by -c, and lets the two play game pairs from random openings, each opening with both
+// colors. The parameters then move towards the engine that scored better, in proportion to the difference
+// of the scores. The perturbation c and the step shrink as the iterations go, on the usual schedules.
+
+// Settings of a tuning run
+type Settings struct {
+	Iterations  int     // Iterations to run in total, counting those of a resumed checkpoint
+	GamePairs   int     // Game pairs per iteration
+	Depth       int     // Search depth of both engines
+	MaxPlies    int     // Plies after which a game is drawn
+	OpeningPly  int     // Random plies of every opening
+	Concurrency int     // Games played at the same time
+	Perturb     float64 // Perturbation of a parameter as a fraction of its starting value, at least 1
+	Rate        float64 // Step, in perturbations, for a game pair won by a full point
+	Stability   float64 // Iterations the step schedule is damped by, 0.1 of the first run's iterations if 0
+	Seed        int64
+}
+
+// DefaultSettings are the settings of the spsa tool unless given otherwise
+var DefaultSettings = Settings{
+	Iterations:  100,
+	GamePairs:   8,
+	Depth:       1,
+	MaxPlies:    200,
+	OpeningPly:  4,
+	Concurrency: 4,
+	Perturb:     0.1,
+	Rate:        1,
+	Seed:        1,
+}
+
+// Checkpoint is the state of a tuning run, written after every iteration so that it can be resumed
+type Checkpoint struct {
+	Evaluator string    // Name the evaluator is registered under
+	Names     []string  // Parameter names, in the order of Params
+	Theta     []float64 // Current parameter values
+	C         []float64 // Starting perturbation of every parameter
+	Iteration int       // Iterations done
+	Settings  Settings
+	History   []Iteration
+}
+
+// Iteration records the games of one iteration
+type Iteration struct {
+	Plus, Minus float64 // Points of the +c and -c engines
+	Theta       []float64
+}
+
+// NewCheckpoint starts a tuning run from the parameters of t
+func NewCheckpoint(name string, t representation.Tunable, settings Settings) *Checkpoint {
+	if settings.Stability == 0 {
+		settings.Stability = 0.1 * float64(settings.Iterations)
+	}
+	cp := &Checkpoint{Evaluator: name, Settings: settings}
+	for _, p := range t.Params() {
+		cp.Names = append(cp.Names, p.Name)
+		cp.Theta = append(cp.Theta, float64(p.Value))
+		cp.C = append(cp.C, math.Max(math.Abs(float64(p.Value))*settings.Perturb, 1))
+	}
+	return cp
+}
+
+// LoadCheckpoint reads a checkpoint file
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(cp.Names) != len(cp.Theta) || len(cp.Names) != len(cp.C) {
+		return nil, fmt.Errorf("%s: %d names, %d values and %d perturbations", path, len(cp.Names), len(cp.Theta), len(cp.C))
+	}
+	if cp.Settings.Stability == 0 {
+		cp.Settings.Stability = 0.1 * float64(cp.Settings.Iterations) // Written before the stability was saved
+	}
+	return cp, nil
+}
+
+// Save writes the checkpoint to path, through a temporary file so that an interrupted write leaves the
+// previous checkpoint intact
+func (cp *Checkpoint) Save(path string) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Values returns the current parameters rounded to integers
+func (cp *Checkpoint) Values() []int {
+	return round(cp.Theta)
+}
+
+func round(theta []float64) []int {
+	values := make([]int, len(theta))
+	for i, v := range theta {
+		values[i] = int(math.Round(v))
+	}
+	return values
+}
+
+// Step runs the next iteration with t as the evaluator whose parameters are tuned. The random openings and
+// signs of iteration k only depend on the seed and k, so a resumed run plays the games it would have played.
+func (cp *Checkpoint) Step(t representation.Tunable) Iteration {
+	s := cp.Settings
+	k := float64(cp.Iteration)
+	rng := rand.New(rand.NewSource(s.Seed + int64(cp.Iteration)*7919))
+
+	// Perturbation and step schedules, both 1 at the first iteration. The stability is fixed when the run
+	// starts, so that changing the iterations of a resumed run does not change the steps taken.
+	stability := s.Stability
+	cScale := math.Pow(k+1, -0.101)
+	aScale := math.Pow((stability+1)/(stability+k+1), 0.602)
+
+	plusTheta, minusTheta := make([]float64, len(cp.Theta)), make([]float64, len(cp.Theta))
+	delta := make([]float64, len(cp.Theta))
+	for i := range cp.Theta {
+		delta[i] = float64(2*rng.Intn(2) - 1)
+		plusTheta[i] = cp.Theta[i] + cp.C[i]*cScale*delta[i]
+		minusTheta[i] = cp.Theta[i] - cp.C[i]*cScale*delta[i]
+	}
+	plus := representation.Engine{Name: "plus", Evaluator: t.WithParams(round(plusTheta)), Depth: s.Depth}
+	minus := representation.Engine{Name: "minus", Evaluator: t.WithParams(round(minusTheta)), Depth: s.Depth}
+
+	openings := representation.OpeningSuite(rng, s.GamePairs, s.OpeningPly)
+	plusPoints, minusPoints := playPairs(plus, minus, openings, s.MaxPlies, s.Concurrency)
+
+	// Move towards the better engine, a point more per game pair moving Rate perturbations
+	perPair := (plusPoints - minusPoints) / float64(max(len(openings), 1))
+	for i := range cp.Theta {
+		cp.Theta[i] += s.Rate * aScale * perPair * cp.C[i] * cScale * delta[i]
+	}
+	cp.Iteration++
+	it := Iteration{Plus: plusPoints, Minus: minusPoints, Theta: append([]float64(nil), cp.Theta...)}
+	cp.History = append(cp.History, it)
+	return it
+}
+
+// playPairs plays every opening with both colors, at most concurrency games at a time, and returns the points
+// of both engines
+func playPairs(a representation.Engine, b representation.Engine, openings []representation.Position, maxPlies int, concurrency int) (float64, float64) {
+	type job struct {
+		opening representation.Position
+		aWhite  bool
+	}
+	jobs := make(chan job)
+	var mu sync.Mutex
+	var aPoints, bPoints float64
+
+	var wg sync.WaitGroup
+	for w := 0; w < max(concurrency, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				white, black := a, b
+				if !j.aWhite {
+					white, black = b, a
+				}
+				g := representation.PlayGame(white, black, j.opening, maxPlies)
+
+				mu.Lock()
+				switch {
+				case g.Result == representation.Draw:
+					aPoints, bPoints = aPoints+0.5, bPoints+0.5
+				case (g.Result == representation.WhiteWins) == j.aWhite:
+					aPoints++
+				default:
+					bPoints++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, opening := range openings {
+		jobs <- job{opening, true}
+		jobs <- job{opening, false}
+	}
+	close(jobs)
+	wg.Wait()
+	return aPoints, bPoints
+}
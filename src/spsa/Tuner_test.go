@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"representation"
+	"testing"
+)
+
+// Test that a run resumed from its checkpoint ends where an uninterrupted run does
+func TestResumeFromCheckpoint(t *testing.T) {
+	e, _ := representation.LookupEvaluator("opening")
+	tunable := e.(representation.Tunable)
+	settings := DefaultSettings
+	settings.Iterations, settings.GamePairs, settings.MaxPlies = 4, 2, 60
+
+	straight := NewCheckpoint("opening", tunable, settings)
+	for straight.Iteration < settings.Iterations {
+		straight.Step(tunable)
+	}
+
+	path := filepath.Join(t.TempDir(), "spsa.json")
+	interrupted := NewCheckpoint("opening", tunable, settings)
+	interrupted.Step(tunable)
+	interrupted.Step(tunable)
+	if err := interrupted.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for resumed.Iteration < settings.Iterations {
+		resumed.Step(tunable)
+	}
+
+	if !reflect.DeepEqual(resumed.Theta, straight.Theta) || len(resumed.History) != 4 {
+		t.Errorf("resumed run ended at %v after %d iterations, uninterrupted run at %v", resumed.Theta, len(resumed.History), straight.Theta)
+	}
+}
+
+// Test that the parameters move towards the engine that scored better, and stay put on a tie
+func TestStepFollowsScores(t *testing.T) {
+	e, _ := representation.LookupEvaluator("opening")
+	tunable := e.(representation.Tunable)
+	settings := DefaultSettings
+	settings.GamePairs, settings.MaxPlies = 4, 100
+
+	cp := NewCheckpoint("opening", tunable, settings)
+	for i := 0; i < 5; i++ {
+		before := append([]float64(nil), cp.Theta...)
+		it := cp.Step(tunable)
+		if it.Plus+it.Minus != float64(2*settings.GamePairs) {
+			t.Fatalf("iteration %d: %v + %v points from %d games", i+1, it.Plus, it.Minus, 2*settings.GamePairs)
+		}
+		if it.Plus == it.Minus && !reflect.DeepEqual(before, cp.Theta) {
+			t.Errorf("iteration %d: a tie moved the parameters from %v to %v", i+1, before, cp.Theta)
+		}
+		if it.Plus != it.Minus && reflect.DeepEqual(before, cp.Theta) {
+			t.Errorf("iteration %d: +c %v, -c %v did not move the parameters", i+1, it.Plus, it.Minus)
+		}
+	}
+}
+
+// Test that a resumed run keeps the iterations of its checkpoint unless --iterations is given
+func TestResumeKeepsIterations(t *testing.T) {
+	e, _ := representation.LookupEvaluator("opening")
+	tunable := e.(representation.Tunable)
+	settings := DefaultSettings
+	settings.Iterations, settings.GamePairs, settings.MaxPlies = 3, 1, 20
+	path := filepath.Join(t.TempDir(), "spsa.json")
+	cp := NewCheckpoint("opening", tunable, settings)
+	cp.Step(tunable)
+	if err := cp.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	args := os.Args
+	defer func() { os.Args = args }()
+	for _, test := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"Spsa", "--checkpoint", path}, 3},
+		{[]string{"Spsa", "--checkpoint", path, "--iterations", "4"}, 4},
+	} {
+		os.Args = test.args
+		if err := SpsaMain(); err != nil {
+			t.Fatal(err)
+		}
+		resumed, err := LoadCheckpoint(path)
+		if err != nil || resumed.Iteration != test.want || resumed.Settings.Stability != cp.Settings.Stability {
+			t.Errorf("%v: resumed run stopped after %d iterations with stability %v, want %d and %v, %v",
+				test.args[1:], resumed.Iteration, resumed.Settings.Stability, test.want, cp.Settings.Stability, err)
+		}
+	}
+}
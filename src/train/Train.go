@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"representation"
+	"runtime"
+)
+
+/*
+Requirements:
+
+ 1. get command line args
+    X --out FILE (the network file to write)
+    X --label outcome|search (optional, outcome by default: learn game results, or the results expected by a search)
+    X --label-eval NAME, --label-depth N, --label-scale K (optional, the search labeling the positions and how its
+      estimates e become expected results, 1 / (1 + exp(-K e)); standard, 2 and 0.001 by default)
+    X --hidden, --epochs, --batch, --rate, --skip, --validation, --seed (optional, see below)
+    X one or more game record files, whose finished games provide the positions
+
+2. output:
+  - number of training and validation positions
+  - training and validation loss after every epoch
+  	- command line
+  - the trained network
+  	- network file, readable by every CLI with --weights FILE
+*/
+
+func TrainMain() error {
+	flags := flag.NewFlagSet("Train", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	out := flags.String("out", "", "network file to write")
+	label := flags.String("label", "outcome", "labels: outcome or search")
+	labelEval := flags.String("label-eval", representation.DefaultEvaluator, "evaluator of the labeling search")
+	labelDepth := flags.Int("label-depth", 2, "depth of the labeling search")
+	labelScale := flags.Float64("label-scale", 0.001, "scale from search estimates to expected results")
+	hidden := flags.Int("hidden", 32, "hidden units")
+	epochs := flags.Int("epochs", 20, "passes over the training positions")
+	batch := flags.Int("batch", 64, "positions per batch")
+	rate := flags.Float64("rate", 0.003, "learning rate")
+	skip := flags.Int("skip", 0, "plies skipped at the start of every game")
+	validation := flags.Float64("validation", 0.1, "fraction of the positions held out for validation")
+	seed := flags.Int64("seed", 1, "random seed")
+	usage := fmt.Errorf("usage: Train --out FILE [--label outcome|search] [--label-eval NAME] [--label-depth N] [--label-scale K] [--hidden N] [--epochs N] [--batch N] [--rate F] [--skip N] [--validation F] [--seed N] <records_file>...")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if *out == "" || flags.NArg() == 0 || *hidden < 1 || *hidden > 65535 || *batch < 1 {
+		return usage
+	}
+
+	var labeler Labeler
+	switch *label {
+	case "outcome":
+		labeler = OutcomeLabel
+	case "search":
+		e, err := representation.LookupEvaluator(*labelEval)
+		if err != nil {
+			return err
+		}
+		labeler = SearchLabel(e, *labelDepth, *labelScale)
+	default:
+		return fmt.Errorf("invalid label: %s", *label)
+	}
+
+	// Read the games of every record file and label their positions
+	var games []*representation.Game
+	for _, file := range flags.Args() {
+		read, err := representation.ReadGameFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read record file: %v", err)
+		}
+		games = append(games, read...)
+	}
+	samples, err := SamplesFromGames(games, *skip, labeler)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("no labeled positions in %d games", len(games))
+	}
+
+	// Hold out the validation positions and train on the rest
+	rng := rand.New(rand.NewSource(*seed))
+	rng.Shuffle(len(samples), func(i, j int) { samples[i], samples[j] = samples[j], samples[i] })
+	held := int(float64(len(samples)) * *validation)
+	fmt.Printf("Positions: %d for training, %d for validation, from %d games\n", len(samples)-held, held, len(games))
+
+	n := representation.NewNetwork(*hidden, rng)
+	opts := Options{Epochs: *epochs, BatchSize: *batch, LearningRate: *rate, Workers: runtime.NumCPU()}
+	loss := Train(n, samples[held:], samples[:held], opts, rng, os.Stdout)
+	fmt.Printf("Final loss: %.5f\n", loss)
+
+	// Write the network file
+	f, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to write network file: %v", err)
+	}
+	if err := representation.WriteNetwork(f, n); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"representation"
+	"sync"
+)
+
+// --- Network training
+
+// Sample is the inputs of a network for one position and the expected result for White it should learn
+type Sample struct {
+	X      [representation.NetworkInputs]float32
+	Target float32
+}
+
+// Labeler returns the target of a position of a game with the given result (1, 0.5 or 0 for White)
+type Labeler func(p representation.Position, result float32) float32
+
+// OutcomeLabel labels every position with the result of its game
+func OutcomeLabel(p representation.Position, result float32) float32 {
+	return result
+}
+
+// SearchLabel labels every position with the expected result of a search of the given depth, its estimate
+// e turned into 1 / (1 + exp(-scale e))
+func SearchLabel(e representation.Evaluator, depth int, scale float64) Labeler {
+	return func(p representation.Position, result float32) float32 {
+		estimate := representation.Search(p, depth, e).Estimate
+		return float32(1 / (1 + math.Exp(-scale*float64(estimate))))
+	}
+}
+
+// SamplesFromGames replays the finished games, skipping the first skip plies of each, and labels their
+// positions. Positions where the game is already over are left out, there is nothing left to evaluate.
+func SamplesFromGames(games []*representation.Game, skip int, label Labeler) ([]Sample, error) {
+	var samples []Sample
+	for i, g := range games {
+		var result float32
+		switch g.Result {
+		case representation.WhiteWins:
+			result = 1
+		case representation.BlackWins:
+			result = 0
+		case representation.Draw:
+			result = 0.5
+		default:
+			continue
+		}
+
+		p := g.Start
+		for ply := 0; ply <= len(g.Moves); ply++ {
+			if ply >= skip && p.Outcome() == representation.Unfinished {
+				x := representation.NetworkFeatures(&p.Board, p.SideToMove, p.WhiteInHand, p.BlackInHand)
+				samples = append(samples, Sample{X: x, Target: label(p, result)})
+			}
+			if ply == len(g.Moves) {
+				break
+			}
+			next, err := p.Play(g.Moves[ply].Move)
+			if err != nil {
+				return nil, fmt.Errorf("game %d, ply %d: %w", i+1, ply+1, err)
+			}
+			p = next
+		}
+	}
+	return samples, nil
+}
+
+// Options of a training run
+type Options struct {
+	Epochs       int
+	BatchSize    int
+	LearningRate float64
+	Workers      int // Goroutines sharing every batch
+}
+
+// adam holds the moment estimates of the Adam optimizer for every weight of a network
+type adam struct {
+	m, v  []float64
+	steps int
+}
+
+// weights returns pointers to the weights of a network, in a fixed order
+func weights(n *representation.Network) []*float32 {
+	ws := make([]*float32, 0, len(n.W1)+len(n.B1)+len(n.W2)+1)
+	for _, layer := range [][]float32{n.W1, n.B1, n.W2} {
+		for i := range layer {
+			ws = append(ws, &layer[i])
+		}
+	}
+	return append(ws, &n.B2)
+}
+
+func zeroLike(n *representation.Network) *representation.Network {
+	return &representation.Network{Hidden: n.Hidden, W1: make([]float32, len(n.W1)), B1: make([]float32, len(n.B1)), W2: make([]float32, len(n.W2))}
+}
+
+// Train fits the network to the training samples with minibatch Adam and reports the mean loss of the
+// training and validation samples after every epoch to log, if log is not nil. It returns the final
+// validation loss, or the training loss if there are no validation samples.
+func Train(n *representation.Network, train []Sample, validation []Sample, opts Options, rng *rand.Rand, log io.Writer) float64 {
+	const beta1, beta2, epsilon = 0.9, 0.999, 1e-8
+	opt := &adam{m: make([]float64, len(weights(n))), v: make([]float64, len(weights(n)))}
+	workers := max(opts.Workers, 1)
+	grads := make([]*representation.Network, workers)
+	for w := range grads {
+		grads[w] = zeroLike(n)
+	}
+
+	order := make([]int, len(train))
+	for i := range order {
+		order[i] = i
+	}
+	final := 0.0
+	for epoch := 1; epoch <= opts.Epochs; epoch++ {
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+
+		trainLoss := 0.0
+		for start := 0; start < len(order); start += opts.BatchSize {
+			batch := order[start:min(start+opts.BatchSize, len(order))]
+			trainLoss += batchGradient(n, train, batch, grads)
+
+			// Sum the workers' gradients into the first and take an Adam step
+			opt.steps++
+			sum := weights(grads[0])
+			for _, g := range grads[1:] {
+				for i, w := range weights(g) {
+					*sum[i] += *w
+				}
+			}
+			for i, w := range weights(n) {
+				g := float64(*sum[i]) / float64(len(batch))
+				opt.m[i] = beta1*opt.m[i] + (1-beta1)*g
+				opt.v[i] = beta2*opt.v[i] + (1-beta2)*g*g
+				mHat := opt.m[i] / (1 - math.Pow(beta1, float64(opt.steps)))
+				vHat := opt.v[i] / (1 - math.Pow(beta2, float64(opt.steps)))
+				*w -= float32(opts.LearningRate * mHat / (math.Sqrt(vHat) + epsilon))
+			}
+		}
+		trainLoss /= float64(max(len(train), 1))
+
+		final = trainLoss
+		if len(validation) > 0 {
+			final = MeanLoss(n, validation)
+		}
+		if log != nil {
+			fmt.Fprintf(log, "epoch %d: training loss %.5f, validation loss %.5f\n", epoch, trainLoss, final)
+		}
+	}
+	return final
+}
+
+// batchGradient clears the workers' gradients, splits the batch between them and returns the summed loss
+func batchGradient(n *representation.Network, samples []Sample, batch []int, grads []*representation.Network) float64 {
+	losses := make([]float64, len(grads))
+	chunk := (len(batch) + len(grads) - 1) / len(grads)
+	var wg sync.WaitGroup
+	for w, g := range grads {
+		for _, weight := range weights(g) {
+			*weight = 0
+		}
+		part := batch[min(w*chunk, len(batch)):min((w+1)*chunk, len(batch))]
+		wg.Add(1)
+		go func(w int, g *representation.Network, part []int) {
+			defer wg.Done()
+			for _, i := range part {
+				losses[w] += float64(n.AddGradient(&samples[i].X, samples[i].Target, g))
+			}
+		}(w, g, part)
+	}
+	wg.Wait()
+
+	total := 0.0
+	for _, loss := range losses {
+		total += loss
+	}
+	return total
+}
+
+// MeanLoss returns the mean cross-entropy loss of the network on the samples
+func MeanLoss(n *representation.Network, samples []Sample) float64 {
+	scratch := zeroLike(n)
+	total := 0.0
+	for i := range samples {
+		total += float64(n.AddGradient(&samples[i].X, samples[i].Target, scratch))
+	}
+	return total / float64(max(len(samples), 1))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"representation"
+	"testing"
+)
+
+// Test that training learns a target that follows the material on the board
+func TestTrainLearnsMaterial(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var samples []Sample
+	for i := 0; i < 2000; i++ {
+		var board representation.MorrisBoard
+		white, black := 0, 0
+		for position := 0; position < 21; position++ {
+			switch rng.Intn(3) {
+			case 1:
+				board.SetPosition(position, representation.White)
+				white++
+			case 2:
+				board.SetPosition(position, representation.Black)
+				black++
+			}
+		}
+		target := float32(0.5)
+		if white > black {
+			target = 1
+		} else if white < black {
+			target = 0
+		}
+		samples = append(samples, Sample{X: representation.NetworkFeatures(&board, representation.White, 0, 0), Target: target})
+	}
+
+	n := representation.NewNetwork(8, rng)
+	before := MeanLoss(n, samples[1800:])
+	after := Train(n, samples[:1800], samples[1800:], Options{Epochs: 10, BatchSize: 32, LearningRate: 0.01, Workers: 2}, rng, nil)
+	if after > before/2 {
+		t.Errorf("validation loss went from %.4f to %.4f, want it at least halved", before, after)
+	}
+}
+
+// Test that positions are labeled with the result of their game, or with a search
+func TestSamplesFromGames(t *testing.T) {
+	g := representation.NewGame("a", "b", "2026.10.18")
+	for _, s := range []string{"a0", "g0", "b1"} {
+		m, _ := representation.ParseMove(s)
+		g.Moves = append(g.Moves, representation.RecordedMove{Move: m})
+	}
+	g.SetResult(representation.WhiteWins)
+
+	samples, err := SamplesFromGames([]*representation.Game{g}, 0, OutcomeLabel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 4 || samples[3].Target != 1 || samples[0].X[43] != 1 || samples[3].X[43] != float32(7)/9 {
+		t.Errorf("got %d samples, the last labeled %v with White in hand %v", len(samples), samples[len(samples)-1].Target, samples[len(samples)-1].X[43])
+	}
+
+	e, _ := representation.LookupEvaluator(representation.DefaultEvaluator)
+	samples, _ = SamplesFromGames([]*representation.Game{g}, 3, SearchLabel(e, 1, 0.001))
+	if len(samples) != 1 || samples[0].Target != 0.5 {
+		t.Errorf("depth 1 search labels %v, want 0.5 for a position even in material", samples)
+	}
+	samples, _ = SamplesFromGames([]*representation.Game{g}, 3, SearchLabel(constant(2000), 1, 0.001))
+	if want := float32(1 / (1 + math.Exp(-2))); len(samples) != 1 || samples[0].Target != want {
+		t.Errorf("search labels %v, want %v for an estimate of 2000", samples, want)
+	}
+}
+
+type constant int
+
+func (c constant) Evaluate(board *representation.MorrisBoard, phase int, sideToMove int) int {
+	return int(c)
+}
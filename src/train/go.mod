@@ -0,0 +1,7 @@
+module train
+
+go 1.22.1
+
+replace representation => ../representation
+
+require representation v0.0.0-00010101000000-000000000000
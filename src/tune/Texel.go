@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"representation"
+	"runtime"
+	"sync"
+)
+
+// --- Texel tuning
+//
+// Every position of a finished game is labeled with the game's result: 1 if White won, 1/2 for a draw and
+// 0 if Black won. A static evaluation e is turned into an expected result with the logistic function
+// 1 / (1 + exp(-k e)), and the tuner looks for the weights whose expected results are closest to the labels,
+// in logistic loss (cross-entropy), with k fitted once to the starting weights.
+
+// Sample is a position labeled with the result of the game it was played in
+type Sample struct {
+	Board      representation.MorrisBoard
+	Phase      int
+	SideToMove int
+	Result     float64 // 1 White won, 0.5 draw, 0 Black won
+}
+
+// AllPhases selects the samples of every phase, where a phase would otherwise be given
+const AllPhases = -1
+
+// SamplesFromGames replays finished games and labels their positions, skipping the first skip plies of each
+// game and, unless phase is AllPhases, positions of other phases
+func SamplesFromGames(games []*representation.Game, skip int, phase int) ([]Sample, error) {
+	var samples []Sample
+	for i, g := range games {
+		var result float64
+		switch g.Result {
+		case representation.WhiteWins:
+			result = 1
+		case representation.BlackWins:
+			result = 0
+		case representation.Draw:
+			result = 0.5
+		default:
+			continue // Unfinished games have no label
+		}
+
+		p := g.Start
+		for ply := 0; ply <= len(g.Moves); ply++ {
+			if ply >= skip && (phase == AllPhases || p.Phase() == phase) {
+				samples = append(samples, Sample{Board: p.Board, Phase: p.Phase(), SideToMove: p.SideToMove, Result: result})
+			}
+			if ply == len(g.Moves) {
+				break
+			}
+			next, err := p.Play(g.Moves[ply].Move)
+			if err != nil {
+				return nil, fmt.Errorf("game %d, ply %d: %w", i+1, ply+1, err)
+			}
+			p = next
+		}
+	}
+	return samples, nil
+}
+
+// Loss returns the mean logistic loss of the expected results of e, scaled by k, against the labels:
+// -(r log p + (1-r) log(1-p)) for a label r and an expected result p. The samples are split between
+// goroutines, one per CPU.
+func Loss(e representation.Evaluator, samples []Sample, k float64) float64 {
+	workers := runtime.NumCPU()
+	chunk := (len(samples) + workers - 1) / workers
+	sums := make([]float64, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start, end := w*chunk, min((w+1)*chunk, len(samples))
+		if start >= end {
+			break
+		}
+		wg.Add(1)
+		go func(w int, part []Sample) {
+			defer wg.Done()
+			for i := range part {
+				s := &part[i]
+				z := k * float64(e.Evaluate(&s.Board, s.Phase, s.SideToMove))
+				sums[w] += s.Result*softplus(-z) + (1-s.Result)*softplus(z)
+			}
+		}(w, samples[start:end])
+	}
+	wg.Wait()
+
+	total := 0.0
+	for _, sum := range sums {
+		total += sum
+	}
+	return total / float64(max(len(samples), 1))
+}
+
+// softplus returns log(1 + exp(x)), which is -log(1 - p) for p = 1 / (1 + exp(-x)), without overflowing on
+// the large estimates of won positions
+func softplus(x float64) float64 {
+	return math.Max(x, 0) + math.Log1p(math.Exp(-math.Abs(x)))
+}
+
+// FitScale returns the k minimizing the loss of e, searched on a logarithmic scale from 1e-7 to 10
+func FitScale(e representation.Evaluator, samples []Sample) float64 {
+	lo, hi := math.Log(1e-7), math.Log(10) // Golden section search on log k
+	ratio := (math.Sqrt(5) - 1) / 2
+	a, b := hi-ratio*(hi-lo), lo+ratio*(hi-lo)
+	la, lb := Loss(e, samples, math.Exp(a)), Loss(e, samples, math.Exp(b))
+	for i := 0; i < 60; i++ {
+		if la < lb {
+			hi, b, lb = b, a, la
+			a = hi - ratio*(hi-lo)
+			la = Loss(e, samples, math.Exp(a))
+		} else {
+			lo, a, la = a, b, lb
+			b = lo + ratio*(hi-lo)
+			lb = Loss(e, samples, math.Exp(b))
+		}
+	}
+	return math.Exp((lo + hi) / 2)
+}
+
+// Tune improves the parameters of t one at a time. Each parameter starts with a step of a quarter of its
+// value, at least 1; a step that lowers the loss is kept, and a step that lowers it in neither direction is
+// halved. Tuning stops after the given number of passes over the parameters, or once every step is down
+// to 1 and no parameter changes. Progress is written to log after every pass, if log is not nil.
+func Tune(t representation.Tunable, samples []Sample, k float64, passes int, log io.Writer) (representation.Tunable, float64) {
+	params := t.Params()
+	values := make([]int, len(params))
+	steps := make([]int, len(params))
+	for i, p := range params {
+		values[i] = p.Value
+		steps[i] = max(abs(p.Value)/4, 1)
+	}
+
+	best := Loss(t, samples, k)
+	for pass := 1; pass <= passes; pass++ {
+		improved := false
+		for i := range values {
+			changed := false
+			for _, direction := range []int{1, -1} {
+				for {
+					values[i] += direction * steps[i]
+					candidate := t.WithParams(values)
+					if loss := Loss(candidate, samples, k); loss < best {
+						t, best, changed = candidate, loss, true
+						continue // Keep going in a direction that helps
+					}
+					values[i] -= direction * steps[i]
+					break
+				}
+			}
+			if changed {
+				improved = true
+			} else if steps[i] > 1 {
+				steps[i] /= 2
+				improved = true // A finer step may still help
+			}
+		}
+		if log != nil {
+			fmt.Fprintf(log, "pass %d: loss %.6f, %s\n", pass, best, formatParams(t.Params()))
+		}
+		if !improved {
+			break
+		}
+	}
+	return t, best
+}
+
+func formatParams(params []representation.Param) string {
+	s := ""
+	for i, p := range params {
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("%s=%d", p.Name, p.Value)
+	}
+	return s
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"representation"
+	"testing"
+)
+
+// Test that positions are labeled with their game's result and filtered by ply and phase
+func TestSamplesFromGames(t *testing.T) {
+	won := representation.NewGame("a", "b", "2026.10.18")
+	for _, s := range []string{"a0", "g0", "b1"} {
+		m, _ := representation.ParseMove(s)
+		won.Moves = append(won.Moves, representation.RecordedMove{Move: m})
+	}
+	won.SetResult(representation.BlackWins)
+	unfinished := representation.NewGame("a", "b", "2026.10.18")
+
+	samples, err := SamplesFromGames([]*representation.Game{won, unfinished}, 1, AllPhases)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 3 {
+		t.Fatalf("got %d samples, want the 3 positions after the first ply", len(samples))
+	}
+	for _, s := range samples {
+		if s.Result != 0 || s.Phase != representation.Opening {
+			t.Errorf("sample %s labeled %v in phase %d, want 0 in the opening", s.Board.String(), s.Result, s.Phase)
+		}
+	}
+	if samples[0].SideToMove != representation.Black || samples[2].Board.String() != "WBWxxxxxxxxxxxxxxxxxx" {
+		t.Errorf("samples start with %d to move and end with %s", samples[0].SideToMove, samples[2].Board.String())
+	}
+
+	if samples, _ := SamplesFromGames([]*representation.Game{won}, 0, representation.MidgameEndgame); len(samples) != 0 {
+		t.Errorf("got %d midgame samples from an opening", len(samples))
+	}
+}
+
+// Test that tuning on engine games lowers the loss, and that the loss is minimal at the fitted scale
+func TestTuneLowersLoss(t *testing.T) {
+	a, _ := representation.NewEngine("opening", 1)
+	b, _ := representation.NewEngine("standard", 1)
+	result := representation.PlayMatch(a, b, representation.OpeningSuite(rand.New(rand.NewSource(1)), 4, 4), 100)
+	samples, err := SamplesFromGames(result.Games, 0, representation.MidgameEndgame)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, _ := representation.LookupEvaluator("midgame")
+	start := e.(representation.Tunable)
+	k := FitScale(start, samples)
+	before := Loss(start, samples, k)
+	if Loss(start, samples, 2*k) < before || Loss(start, samples, k/2) < before {
+		t.Errorf("scale %g does not minimize the loss", k)
+	}
+
+	tuned, after := Tune(start, samples, k, 2, nil)
+	if after > before || math.Abs(after-Loss(tuned, samples, k)) > 1e-12 {
+		t.Errorf("tuning from loss %f ended at %f, tuned weights have loss %f", before, after, Loss(tuned, samples, k))
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"representation"
+	"strings"
+)
+
+/*
+Requirements:
+
+ 1. get command line args
+    X --eval NAME (a registered evaluator with weights, opening or midgame) or --weights FILE (a weights file to start from)
+    X --out FILE (the weights file to write)
+    X --phase all|opening|midgame (optional, the positions to tune on, all by default)
+    X --skip N (optional, plies skipped at the start of every game, 0 by default)
+    X --passes N (optional, the most passes over the weights, 100 by default)
+    X one or more game record files, whose finished games label their positions with the result
+
+2. output:
+  - number of labeled positions, the fitted scale k and the loss of the starting weights
+  - loss and weights after every pass
+  - final loss and the tuned weights
+  	- command line
+  	- weights file, readable by every CLI with --weights FILE
+*/
+
+func TuneMain() error {
+	flags := flag.NewFlagSet("Tune", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	evalName := flags.String("eval", "midgame", "evaluator to tune")
+	weights := flags.String("weights", "", "weights file to start from")
+	out := flags.String("out", "", "weights file to write")
+	phaseName := flags.String("phase", "all", "positions to tune on: all, opening or midgame")
+	skip := flags.Int("skip", 0, "plies skipped at the start of every game")
+	passes := flags.Int("passes", 100, "most passes over the weights")
+	usage := fmt.Errorf("usage: Tune [--eval NAME | --weights FILE] --out FILE [--phase all|opening|midgame] [--skip N] [--passes N] <records_file>...")
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		return fmt.Errorf("%v\n%v", err, usage)
+	}
+	if *out == "" || flags.NArg() == 0 {
+		return usage
+	}
+
+	phase := AllPhases
+	switch *phaseName {
+	case "all":
+	case "opening":
+		phase = representation.Opening
+	case "midgame":
+		phase = representation.MidgameEndgame
+	default:
+		return fmt.Errorf("invalid phase: %s", *phaseName)
+	}
+
+	// Evaluator to start from, with its registered weights or those of a weights file
+	var tunable representation.Tunable
+	if *weights != "" {
+		t, name, err := representation.LoadWeights(*weights)
+		if err != nil {
+			return err
+		}
+		tunable, *evalName = t, name
+	} else {
+		e, err := representation.LookupEvaluator(*evalName)
+		if err != nil {
+			return err
+		}
+		t, ok := e.(representation.Tunable)
+		if !ok {
+			return fmt.Errorf("evaluator %s has no weights to tune", *evalName)
+		}
+		tunable = t
+	}
+
+	// Read the games of every record file
+	var games []*representation.Game
+	for _, file := range flags.Args() {
+		read, err := representation.ReadGameFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read record file: %v", err)
+		}
+		games = append(games, read...)
+	}
+	samples, err := SamplesFromGames(games, *skip, phase)
+	if err != nil {
+		return err
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("no labeled positions in %d games", len(games))
+	}
+
+	k := FitScale(tunable, samples)
+	fmt.Printf("Positions: %d from %d games\n", len(samples), len(games))
+	fmt.Printf("Scale k: %.3g\n", k)
+	fmt.Printf("Starting loss: %.6f, %s\n", Loss(tunable, samples, k), formatParams(tunable.Params()))
+
+	tuned, loss := Tune(tunable, samples, k, *passes, os.Stdout)
+	fmt.Printf("Tuned loss: %.6f, %s\n", loss, formatParams(tuned.Params()))
+
+	// Write the tuned weights file
+	f, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to write weights file: %v", err)
+	}
+	comment := fmt.Sprintf("Tuned from %d positions of %d games in %s, k %.3g, loss %.6f",
+		len(samples), len(games), strings.Join(flags.Args(), " "), k, loss)
+	if err := representation.WriteWeights(f, *evalName, tuned, comment); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
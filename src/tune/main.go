@@ -0,0 +1,8 @@
+package main
+
+import "fmt"
+
+func main() {
+	err := TuneMain()
+	fmt.Printf("%v", err)
+}